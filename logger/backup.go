@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 切分出来的备份文件的保留和压缩配置
+type backupConfig struct {
+	maxBackups int           // 最多保留的备份个数，0表示不限制
+	maxAge     time.Duration // 备份最长保留时间，0表示不限制
+	compress   bool          // 备份是否gzip压缩
+}
+
+func newBackupConfig(config map[string]string) backupConfig {
+	backup := backupConfig{
+		maxBackups: configInt(config, "log_max_backups", 0),
+		compress:   config["log_compress"] == "true",
+	}
+
+	// log_max_age 单位为天
+	if days := configInt(config, "log_max_age", 0); days > 0 {
+		backup.maxAge = time.Duration(days) * 24 * time.Hour
+	}
+	return backup
+}
+
+func (b backupConfig) enabled() bool {
+	return b.maxBackups > 0 || b.maxAge > 0 || b.compress
+}
+
+// backupPrefix 备份文件名的前缀，每个输出文件分开统计
+func backupPrefix(lf *logFile) string {
+	return lf.name + "_"
+}
+
+// cleanBackups 在后台压缩刚切分出来的备份文件，然后按个数和时间清理旧备份
+// backupFilename 为空时只做清理
+func (f *FileLogger) cleanBackups(backupFilename string, lf *logFile) {
+	if !f.backup.enabled() {
+		return
+	}
+
+	prefix := backupPrefix(lf)
+	f.cleanWg.Add(1)
+	go func() {
+		defer f.cleanWg.Done()
+
+		// 同一时间只有一个清理任务，避免压缩和删除同一个文件
+		f.cleanLock.Lock()
+		defer f.cleanLock.Unlock()
+
+		if f.backup.compress {
+			if backupFilename != "" {
+				compressFile(backupFilename)
+			}
+			// 上次进程退出时没来得及压缩的备份
+			for _, backup := range f.listBackups(prefix) {
+				if !strings.HasSuffix(backup.name, ".gz") {
+					compressFile(backup.name)
+				}
+			}
+		}
+
+		f.removeBackups(prefix)
+	}()
+}
+
+type backupFile struct {
+	name    string
+	modTime time.Time
+}
+
+// listBackups 返回所有备份文件，最新的在前面
+func (f *FileLogger) listBackups(prefix string) []backupFile {
+	entries, err := os.ReadDir(f.logPath)
+	if err != nil {
+		return nil
+	}
+
+	backups := make([]backupFile, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{
+			name:    filepath.Join(f.logPath, entry.Name()),
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+	return backups
+}
+
+func (f *FileLogger) removeBackups(prefix string) {
+	if f.backup.maxBackups <= 0 && f.backup.maxAge <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for i, backup := range f.listBackups(prefix) {
+		if f.backup.maxBackups > 0 && i >= f.backup.maxBackups {
+			os.Remove(backup.name)
+			continue
+		}
+		if f.backup.maxAge > 0 && now.Sub(backup.modTime) > f.backup.maxAge {
+			os.Remove(backup.name)
+		}
+	}
+}
+
+// compressFile 把文件压缩成 filename.gz，成功后删除原文件
+func compressFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	gzFilename := filename + ".gz"
+	dst, err := os.OpenFile(gzFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(gzFilename)
+		return err
+	}
+
+	// 保留原文件的修改时间，按时间清理时才准确
+	os.Chtimes(gzFilename, info.ModTime(), info.ModTime())
+	src.Close()
+	return os.Remove(filename)
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// 本包的路径，取调用位置时跳过本包和loggertest内的函数
+	loggerPackage = reflect.TypeOf(Entry{}).PkgPath()
+
+	callerDisabled atomic.Bool  // 关闭后不再获取调用位置
+	callerSkip     atomic.Int32 // 业务自己封装日志函数时额外跳过的层数
+	callerFullPath atomic.Bool  // 文件名输出完整路径
+)
+
+// EnableCaller 打开或关闭调用位置的获取，关闭后日志里没有文件名和行号
+// 关闭后 SetPackageLevel 的规则不再生效，采样改为按格式化之前的消息区分
+func EnableCaller(enable bool) {
+	callerDisabled.Store(!enable)
+}
+
+// SetCallerSkip 设置全局额外跳过的调用层数
+func SetCallerSkip(skip int) {
+	if skip < 0 {
+		skip = 0
+	}
+	callerSkip.Store(int32(skip))
+}
+
+// SetCallerFullPath 文件名使用完整路径还是只保留文件名
+func SetCallerFullPath(fullPath bool) {
+	callerFullPath.Store(fullPath)
+}
+
+// configureCaller 读取 log_caller(false关闭), log_caller_skip, log_caller_path(full/base)
+func configureCaller(config map[string]string) {
+	EnableCaller(config["log_caller"] != "false")
+	SetCallerSkip(configInt(config, "log_caller_skip", 0))
+	SetCallerFullPath(config["log_caller_path"] == "full")
+}
+
+func inLoggerPackage(function string) bool {
+	return strings.HasPrefix(function, loggerPackage+".") || strings.HasPrefix(function, loggerPackage+"/loggertest.")
+}
+
+// GetLineInfo 返回调用日志的业务代码位置
+// 跳过本包和loggertest内的所有函数，再跳过 SetCallerSkip 设置的层数
+func GetLineInfo() (fileName string, funcName string, lineNo int) {
+	return getLineInfo(0)
+}
+
+func getLineInfo(skip int) (fileName string, funcName string, lineNo int) {
+	if callerDisabled.Load() {
+		return
+	}
+
+	skip += int(callerSkip.Load())
+	// 本包内的调用一般不超过6层，先取少量的栈帧，不够时再取完整的栈
+	var pcs [64]uintptr
+	for _, depth := range [...]int{skip + 10, len(pcs)} {
+		if depth > len(pcs) {
+			depth = len(pcs)
+		}
+		n := runtime.Callers(2, pcs[:depth])
+		remain := skip
+		for _, pc := range pcs[:n] {
+			for _, frame := range callerFrames(pc) {
+				if frame.inLogger {
+					continue
+				}
+				if remain == 0 {
+					return frame.file, frame.function, frame.line
+				}
+				remain--
+			}
+		}
+		if n < depth {
+			return
+		}
+	}
+	return
+}
+
+type callerFrame struct {
+	file     string
+	function string
+	line     int
+	inLogger bool
+}
+
+// 解析过的pc，解析一次栈帧的开销比获取pc大很多
+var callerCache sync.Map // uintptr -> []callerFrame
+
+// callerFrames 返回pc对应的栈帧，内联的函数会展开成多个
+func callerFrames(pc uintptr) []callerFrame {
+	if cached, ok := callerCache.Load(pc); ok {
+		return cached.([]callerFrame)
+	}
+
+	var result []callerFrame
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		result = append(result, callerFrame{
+			file:     frame.File,
+			function: frame.Function,
+			line:     frame.Line,
+			inLogger: inLoggerPackage(frame.Function),
+		})
+		if !more {
+			break
+		}
+	}
+	callerCache.Store(pc, result)
+	return result
+}
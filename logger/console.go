@@ -5,8 +5,24 @@ import (
 	"os"
 )
 
+// 各级别的颜色
+var levelColors = map[int]string{
+	LogLevelDebug: "\033[90m",
+	LogLevelTrace: "\033[36m",
+	LogLevelInfo:  "\033[32m",
+	LogLevelWarn:  "\033[33m",
+	LogLevelError: "\033[31m",
+	LogLevelFatal: "\033[1;31m",
+}
+
+const colorReset = "\033[0m"
+
 type ConsoleLogger struct {
-	level int
+	level       logLevel
+	formatter   Formatter
+	stdoutColor bool
+	stderrColor bool
+	stderrLevel int // 达到这个级别的日志输出到stderr
 }
 
 func NewConsoleLogger(config map[string]string) (log LogInterface, err error) {
@@ -16,13 +32,69 @@ func NewConsoleLogger(config map[string]string) (log LogInterface, err error) {
 		return
 	}
 
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return
+	}
+
+	// log_stderr_level: 达到这个级别的日志输出到stderr，默认warn，配置none全部输出到stdout
+	stderrLevel := LogLevelWarn
+	if name, ok := config["log_stderr_level"]; ok && name != "" {
+		if name == "none" {
+			stderrLevel = LogLevelFatal + 1
+		} else if stderrLevel, err = ParseLevel(name); err != nil {
+			err = fmt.Errorf("unsupport log_stderr_level:%s", name)
+			return
+		}
+	}
+
+	// log_color: auto, always, never
+	// auto 在输出是终端并且没有设置 NO_COLOR 时才使用颜色
+	var stdoutColor, stderrColor bool
+	switch config["log_color"] {
+	case "", "auto":
+		stdoutColor = colorTerminal(os.Stdout)
+		stderrColor = colorTerminal(os.Stderr)
+	case "always":
+		stdoutColor, stderrColor = true, true
+	case "never":
+	default:
+		err = fmt.Errorf("unsupport log_color:%s", config["log_color"])
+		return
+	}
+
+	// JSON和自定义格式不加颜色
+	switch formatter.(type) {
+	case *TextFormatter, *DevFormatter:
+	default:
+		stdoutColor, stderrColor = false, false
+	}
+
 	level := getLogLevel(logLevel)
-	log = &ConsoleLogger{
-		level: level,
+	console := &ConsoleLogger{
+		formatter:   formatter,
+		stdoutColor: stdoutColor,
+		stderrColor: stderrColor,
+		stderrLevel: stderrLevel,
 	}
+	console.level.set(level)
+	log = console
 	return
 }
 
+// colorTerminal 判断输出是否是支持颜色的终端
+func colorTerminal(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	statInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return statInfo.Mode()&os.ModeCharDevice != 0
+}
+
 func (c *ConsoleLogger) Init() {
 
 }
@@ -32,67 +104,103 @@ func (c *ConsoleLogger) SetLevel(level int) {
 		level = LogLevelDebug
 	}
 
-	c.level = level
+	c.level.set(level)
+}
+
+func (c *ConsoleLogger) GetLevel() int {
+	return c.level.get()
 }
 
 func (c *ConsoleLogger) Debug(format string, args ...interface{}) {
-	if c.level > LogLevelDebug {
+	if !c.level.enabled(LogLevelDebug) {
 		return
 	}
 
 	logData := writeLog(LogLevelDebug, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 
 func (c *ConsoleLogger) Trace(format string, args ...interface{}) {
-	if c.level > LogLevelTrace {
+	if !c.level.enabled(LogLevelTrace) {
 		return
 	}
 
 	logData := writeLog(LogLevelTrace, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 func (c *ConsoleLogger) Info(format string, args ...interface{}) {
-	if c.level > LogLevelInfo {
+	if !c.level.enabled(LogLevelInfo) {
 		return
 	}
 
 	logData := writeLog(LogLevelInfo, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 
 func (c *ConsoleLogger) Warn(format string, args ...interface{}) {
-	if c.level > LogLevelWarn {
+	if !c.level.enabled(LogLevelWarn) {
 		return
 	}
 
 	logData := writeLog(LogLevelWarn, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 
 func (c *ConsoleLogger) Error(format string, args ...interface{}) {
-	if c.level > LogLevelError {
+	if !c.level.enabled(LogLevelError) {
 		return
 	}
 
 	logData := writeLog(LogLevelError, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 func (c *ConsoleLogger) Fatal(format string, args ...interface{}) {
-	if c.level > LogLevelFatal {
+	if !c.level.enabled(LogLevelFatal) {
 		return
 	}
 
 	logData := writeLog(LogLevelFatal, format, args...)
-	fmt.Fprintf(os.Stdout, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-		logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+	c.Output(logData)
 }
 
-func (c *ConsoleLogger) Close() {
+func (c *ConsoleLogger) Logw(level int, msg string, fields ...Field) {
+	if !c.level.enabled(level) {
+		return
+	}
+
+	logData := writeLogw(level, msg, fields)
+	c.Output(logData)
+}
 
+func (c *ConsoleLogger) Output(logData *LogData) {
+	if !c.level.allow(logData) || !sample(logData, c.output) {
+		return
+	}
+
+	c.output(logData)
+}
+
+func (c *ConsoleLogger) output(logData *LogData) {
+	file, color := os.Stdout, c.stdoutColor
+	if logData.Level >= c.stderrLevel {
+		file, color = os.Stderr, c.stderrColor
+	}
+
+	// 复制一份再修改级别文本，LogData可能还会输出到其它地方
+	if color {
+		colored := *logData
+		colored.LevelStr = levelColors[logData.Level] + logData.LevelStr + colorReset
+		logData = &colored
+	}
+	file.Write(c.formatter.Format(logData))
+}
+
+func (c *ConsoleLogger) Flush() {
+	flushSampling()
+	os.Stdout.Sync()
+	os.Stderr.Sync()
+}
+
+func (c *ConsoleLogger) Close() {
+	flushSampling()
 }
@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+)
+
+// captureConsole 把stdout和stderr换成临时文件，返回两个文件的内容
+func captureConsole(t *testing.T, config map[string]string, log func(l logger.LogInterface)) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+	}()
+
+	l, err := logger.NewConsoleLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log(l)
+
+	out, _ := os.ReadFile(stdout.Name())
+	errOut, _ := os.ReadFile(stderr.Name())
+	return string(out), string(errOut)
+}
+
+func TestConsoleStderrLevel(t *testing.T) {
+	logAll := func(l logger.LogInterface) {
+		l.Info("info line")
+		l.Warn("warn line")
+		l.Error("error line")
+	}
+
+	cases := []struct {
+		stderrLevel string
+		stdout      []string
+		stderr      []string
+	}{
+		// 默认WARN及以上输出到stderr
+		{"", []string{"info line"}, []string{"warn line", "error line"}},
+		{"error", []string{"info line", "warn line"}, []string{"error line"}},
+		{"none", []string{"info line", "warn line", "error line"}, nil},
+	}
+	for _, c := range cases {
+		config := map[string]string{"log_level": "debug", "log_color": "never"}
+		if c.stderrLevel != "" {
+			config["log_stderr_level"] = c.stderrLevel
+		}
+		stdout, stderr := captureConsole(t, config, logAll)
+		for _, line := range []string{"info line", "warn line", "error line"} {
+			if want := slices.Contains(c.stdout, line); strings.Contains(stdout, line) != want {
+				t.Errorf("log_stderr_level=%q: %q in stdout = %v, want %v", c.stderrLevel, line, !want, want)
+			}
+			if want := slices.Contains(c.stderr, line); strings.Contains(stderr, line) != want {
+				t.Errorf("log_stderr_level=%q: %q in stderr = %v, want %v", c.stderrLevel, line, !want, want)
+			}
+		}
+	}
+}
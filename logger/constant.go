@@ -12,8 +12,30 @@ const (
 const (
 	LogSplitTypeHour = iota
 	LogSplitTypeSize
+	LogSplitTypeDay
+	LogSplitTypeMinute
 )
 
+// 异步队列满了之后的处理策略
+const (
+	LogOverflowDrop         = iota // 直接丢弃新日志
+	LogOverflowBlock               // 阻塞直到队列有空位
+	LogOverflowBlockTimeout        // 阻塞等待一段时间，超时丢弃
+	LogOverflowDropLowest          // 队列快满时先丢弃WARN以下的日志，给高级别的日志留出空间
+)
+
+func getOverflowPolicy(policy string) int {
+	switch policy {
+	case "block":
+		return LogOverflowBlock
+	case "block_timeout":
+		return LogOverflowBlockTimeout
+	case "drop_lowest":
+		return LogOverflowDropLowest
+	}
+	return LogOverflowDrop
+}
+
 func getLevelText(level int) string {
 	switch level {
 	case LogLevelDebug:
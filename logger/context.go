@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+)
+
+// 从context中取出并附加到日志上的字段名
+const (
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+)
+
+type contextKey struct {
+	name string
+}
+
+var (
+	traceIDKey   = &contextKey{FieldTraceID}
+	spanIDKey    = &contextKey{FieldSpanID}
+	requestIDKey = &contextKey{FieldRequestID}
+	userIDKey    = &contextKey{FieldUserID}
+
+	contextKeys = []*contextKey{traceIDKey, spanIDKey, requestIDKey, userIDKey}
+)
+
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, spanIDKey, spanID)
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+func TraceIDFromContext(ctx context.Context) string {
+	return contextString(ctx, traceIDKey)
+}
+
+func SpanIDFromContext(ctx context.Context) string {
+	return contextString(ctx, spanIDKey)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	return contextString(ctx, requestIDKey)
+}
+
+func UserIDFromContext(ctx context.Context) string {
+	return contextString(ctx, userIDKey)
+}
+
+func contextString(ctx context.Context, key *contextKey) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+// contextFields 取出context里所有已设置的ID
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []Field
+	for _, key := range contextKeys {
+		if value := contextString(ctx, key); value != "" {
+			fields = append(fields, Field{Key: key.name, Value: value})
+		}
+	}
+	return fields
+}
+
+// WithContext 返回携带context里trace/request等ID的Entry
+func WithContext(ctx context.Context) *Entry {
+	return &Entry{fields: contextFields(ctx)}
+}
+
+// WithContext 在当前Entry的字段之后追加context里的ID
+func (e *Entry) WithContext(ctx context.Context) *Entry {
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return e
+	}
+	merged := make([]Field, 0, len(e.fields)+len(fields))
+	merged = append(merged, e.fields...)
+	merged = append(merged, fields...)
+	return &Entry{fields: merged, skip: e.skip}
+}
+
+// logCtx 级别不够时不格式化消息，和writeLog一样按格式化之前的消息采样
+func logCtx(ctx context.Context, level int, format string, args []interface{}) {
+	if !logEnabled(level) {
+		return
+	}
+
+	fileName, funcName, lineNo := getLineInfo(0)
+	logData := newLogData(level, fmt.Sprintf(format, args...), contextFields(ctx), fileName, funcName, lineNo)
+	logData.format = format
+	getLog().Output(logData)
+}
+
+func DebugCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelDebug, format, args)
+}
+
+func TraceCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelTrace, format, args)
+}
+
+func InfoCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelInfo, format, args)
+}
+
+func WarnCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelWarn, format, args)
+}
+
+func ErrorCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelError, format, args)
+}
+
+func FatalCtx(ctx context.Context, format string, args ...interface{}) {
+	logCtx(ctx, LogLevelFatal, format, args)
+	exitOnFatal()
+}
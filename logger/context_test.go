@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// countingStringer 记录被格式化的次数
+type countingStringer struct {
+	calls int
+}
+
+func (c *countingStringer) String() string {
+	c.calls++
+	return "value"
+}
+
+func TestLogCtxFields(t *testing.T) {
+	rec := loggertest.Install(t)
+	ctx := logger.WithRequestID(logger.WithTraceID(context.Background(), "t-1"), "r-1")
+
+	logger.InfoCtx(ctx, "handled %s", "GET")
+	entries := rec.Entries()
+	if len(entries) != 1 || entries[0].Message != "handled GET" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	fields := map[string]interface{}{}
+	for _, field := range entries[0].Fields {
+		fields[field.Key] = field.Value
+	}
+	if fields[logger.FieldTraceID] != "t-1" || fields[logger.FieldRequestID] != "r-1" {
+		t.Errorf("fields = %v", fields)
+	}
+}
+
+// 级别不够时不格式化消息
+func TestLogCtxDisabledSkipsFormatting(t *testing.T) {
+	rec := loggertest.Install(t)
+	rec.SetLevel(logger.LogLevelInfo)
+
+	arg := &countingStringer{}
+	logger.DebugCtx(context.Background(), "debug %s", arg)
+	if arg.calls != 0 {
+		t.Errorf("argument formatted %d times for a disabled level", arg.calls)
+	}
+	rec.AssertNotLogged(t, logger.LogLevelDebug, "debug")
+}
+
+// 关闭调用位置时按格式化之前的消息采样，每个格式只输出第一条
+func TestLogCtxSamplingWithoutCaller(t *testing.T) {
+	rec := loggertest.New()
+	multi := &logger.MultiLogger{}
+	multi.AddSink(rec)
+	old := logger.SetLogger(multi)
+	logger.SetSampling(1, 0, time.Hour)
+	logger.EnableCaller(false)
+	t.Cleanup(func() {
+		logger.EnableCaller(true)
+		logger.SetSampling(0, 0, 0)
+		logger.SetLogger(old)
+	})
+
+	for i := 0; i < 3; i++ {
+		logger.InfoCtx(context.Background(), "first %d", i)
+		logger.InfoCtx(context.Background(), "second %d", i)
+	}
+	if n := len(rec.Entries()); n != 2 {
+		t.Errorf("logged %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+// Entry 携带一组结构化字段的日志记录器
+// logger.With("conn_id", id).Info("connected", "addr", addr)
+type Entry struct {
+	fields []Field
+	skip   int
+}
+
+func With(kv ...interface{}) *Entry {
+	return &Entry{fields: toFields(kv)}
+}
+
+// WithCallerSkip 业务封装日志函数时使用，skip为封装的层数
+//
+//	func logConn(id uint64, msg string) {
+//		logger.WithCallerSkip(1).Info(msg, "conn_id", id)
+//	}
+func WithCallerSkip(skip int) *Entry {
+	return &Entry{skip: skip}
+}
+
+// With 返回一个新的Entry，包含当前Entry的字段和新增的字段
+func (e *Entry) With(kv ...interface{}) *Entry {
+	return &Entry{fields: e.withFields(kv), skip: e.skip}
+}
+
+func (e *Entry) WithCallerSkip(skip int) *Entry {
+	return &Entry{fields: e.fields, skip: e.skip + skip}
+}
+
+func (e *Entry) withFields(kv []interface{}) []Field {
+	if len(kv) == 0 {
+		return e.fields
+	}
+
+	fields := make([]Field, 0, len(e.fields)+(len(kv)+1)/2)
+	fields = append(fields, e.fields...)
+	fields = append(fields, toFields(kv)...)
+	return fields
+}
+
+func (e *Entry) log(level int, msg string, kv []interface{}) {
+	if !logEnabled(level) {
+		return
+	}
+
+	fileName, funcName, lineNo := getLineInfo(e.skip)
+	getLog().Output(newLogData(level, msg, e.withFields(kv), fileName, funcName, lineNo))
+}
+
+func (e *Entry) Debug(msg string, kv ...interface{}) {
+	e.log(LogLevelDebug, msg, kv)
+}
+
+func (e *Entry) Trace(msg string, kv ...interface{}) {
+	e.log(LogLevelTrace, msg, kv)
+}
+
+func (e *Entry) Info(msg string, kv ...interface{}) {
+	e.log(LogLevelInfo, msg, kv)
+}
+
+func (e *Entry) Warn(msg string, kv ...interface{}) {
+	e.log(LogLevelWarn, msg, kv)
+}
+
+func (e *Entry) Error(msg string, kv ...interface{}) {
+	e.log(LogLevelError, msg, kv)
+}
+
+func (e *Entry) Fatal(msg string, kv ...interface{}) {
+	e.log(LogLevelFatal, msg, kv)
+	exitOnFatal()
+}
@@ -1,26 +1,64 @@
 package logger
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// logFile 一个输出文件和它的切分状态，只在后台协程中访问
+type logFile struct {
+	name       string // 文件名，如 app.log、app.log.wf
+	file       *os.File
+	writer     *bufio.Writer // 写缓冲，不使用缓冲时为nil
+	size       int64         // 文件大小，包含缓冲区里还没写入的部分
+	splitStart time.Time     // 文件内日志所属周期的起始时间
+}
+
 // 2018/3/26 0:01.383 DEBUG logDebug.go:29 this is a debug log
 // 2006-01-02 15:04:05.999
 type FileLogger struct {
-	level         int
-	logPath       string
-	logName       string
-	file          *os.File
-	warnFile      *os.File
-	logSplitType  int
-	logSplitSize  int64
-	lastSplitHour int
-
-	logDataChan chan *LogData
-	closeChan   chan struct{}
+	level        logLevel
+	logPath      string
+	logName      string
+	logSplitType int
+	logSplitSize int64
+
+	logSplitInterval int // 按分钟切分的间隔
+
+	bufferSize    int           // 写缓冲的大小，0表示每条日志直接写文件
+	flushInterval time.Duration // 写缓冲定时刷到文件的间隔
+
+	wfLevel     int                           // 达到这个级别的日志写入 .log.wf
+	wfDuplicate bool                          // 写入其它文件的日志同时写一份到 .log
+	levelFiles  []int                         // 单独写一个文件的级别
+	files       []*logFile                    // 所有的输出文件，第一个是 .log
+	routes      [LogLevelFatal + 1][]*logFile // 每个级别写入的文件
+
+	formatter    Formatter
+	logDataChan  chan *LogData
+	closeChan    chan struct{}
+	flushChan    chan chan struct{}
+	doneChan     chan struct{}
+	closeOnce    sync.Once
+	closed       atomic.Bool
+	closeLock    sync.RWMutex // 入队时持有读锁，关闭时等入队完成后再通知后台协程
+	closeTimeout time.Duration
+
+	overflowPolicy     int
+	overflowTimeout    time.Duration
+	dropReportInterval int
+	dropped            [LogLevelFatal + 1]atomic.Int64
+	dropReported       [LogLevelFatal + 1]int64
+
+	backup    backupConfig
+	cleanLock sync.Mutex
+	cleanWg   sync.WaitGroup
 }
 
 func NewFileLogger(config map[string]string) (log LogInterface, err error) {
@@ -47,46 +85,132 @@ func NewFileLogger(config map[string]string) (log LogInterface, err error) {
 		logChanSize = "50000"
 	}
 
+	// log_split_type: hour, day, minute, size
+	// 按时间切分时如果配置了 log_split_size，超过大小也会切分
 	var logSplitType int = LogSplitTypeHour
 	var logSplitSize int64
 	logSplitStr, ok := config["log_split_type"]
 	if !ok {
 		logSplitStr = "hour"
-	} else {
-		if logSplitStr == "size" {
-			logSplitSizeStr, ok := config["log_split_size"]
-			if !ok {
-				logSplitSizeStr = "104857600"
-			}
+	}
+	switch logSplitStr {
+	case "size":
+		logSplitType = LogSplitTypeSize
+	case "day":
+		logSplitType = LogSplitTypeDay
+	case "minute":
+		logSplitType = LogSplitTypeMinute
+	default:
+		logSplitType = LogSplitTypeHour
+	}
 
-			logSplitSize, err = strconv.ParseInt(logSplitSizeStr, 10, 64)
-			if err != nil {
-				logSplitSize = 104857600
-			}
+	logSplitSizeStr, ok := config["log_split_size"]
+	if ok || logSplitType == LogSplitTypeSize {
+		if !ok {
+			logSplitSizeStr = "104857600"
+		}
 
-			logSplitType = LogSplitTypeSize
-		} else {
-			logSplitType = LogSplitTypeHour
+		logSplitSize, err = strconv.ParseInt(logSplitSizeStr, 10, 64)
+		if err != nil {
+			logSplitSize = 104857600
 		}
 	}
 
+	// 按分钟切分的间隔，单位分钟，需要能整除一天
+	logSplitInterval := configInt(config, "log_split_interval", 10)
+	if logSplitInterval <= 0 || 24*60%logSplitInterval != 0 {
+		logSplitInterval = 10
+	}
+
 	chanSize, err := strconv.Atoi(logChanSize)
 	if err != nil {
 		chanSize = 50000
 	}
 
-	level := getLogLevel(logLevel)
-	log = &FileLogger{
-		level:         level,
-		logPath:       logPath,
-		logName:       logName,
-		logSplitSize:  logSplitSize,
-		logSplitType:  logSplitType,
-		lastSplitHour: time.Now().Hour(),
-		logDataChan:   make(chan *LogData, chanSize),
-		closeChan:     make(chan struct{}),
+	// 关闭时等待日志落盘的超时时间，单位毫秒，不配置则一直等待
+	var closeTimeout time.Duration
+	if ms := configInt(config, "log_close_timeout", 0); ms > 0 {
+		closeTimeout = time.Duration(ms) * time.Millisecond
+	}
+
+	// 队列满时的处理策略：drop, block, block_timeout, drop_lowest
+	overflowPolicy := getOverflowPolicy(config["log_overflow_policy"])
+	overflowTimeout := time.Duration(configInt(config, "log_overflow_timeout", 100)) * time.Millisecond
+	// 丢弃日志统计的输出间隔，单位秒
+	dropReportInterval := configInt(config, "log_drop_report_interval", 60)
+	if dropReportInterval <= 0 {
+		dropReportInterval = 60
+	}
+
+	// 写缓冲的大小，单位字节，缓冲满或者到了 log_flush_interval(毫秒) 时写入文件
+	// ERROR及以上的日志写完立即刷到文件，进程崩溃时不会丢失
+	bufferSize := configInt(config, "log_write_buffer", 256*1024)
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	flushInterval := time.Duration(configInt(config, "log_flush_interval", 1000)) * time.Millisecond
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	// log_wf_level: 达到这个级别的日志写入 .log.wf，默认warn，配置none不使用 .log.wf
+	wfLevel := LogLevelWarn
+	if name, ok := config["log_wf_level"]; ok && name != "" {
+		if name == "none" {
+			wfLevel = LogLevelFatal + 1
+		} else if wfLevel, err = ParseLevel(name); err != nil {
+			err = fmt.Errorf("unsupport log_wf_level:%s", name)
+			return
+		}
+	}
+
+	// log_level_files: 单独写一个文件的级别，如 error,fatal 写入 app.log.error 和 app.log.fatal
+	var levelFiles []int
+	for _, name := range strings.Split(config["log_level_files"], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		level, parseErr := ParseLevel(name)
+		if parseErr != nil {
+			err = fmt.Errorf("unsupport log_level_files:%s", name)
+			return
+		}
+		levelFiles = append(levelFiles, level)
+	}
+
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return
 	}
 
+	level := getLogLevel(logLevel)
+	fileLogger := &FileLogger{
+		logPath:          logPath,
+		logName:          logName,
+		logSplitSize:     logSplitSize,
+		logSplitType:     logSplitType,
+		logSplitInterval: logSplitInterval,
+		bufferSize:       bufferSize,
+		flushInterval:    flushInterval,
+		wfLevel:          wfLevel,
+		wfDuplicate:      config["log_wf_duplicate"] == "true",
+		levelFiles:       levelFiles,
+		formatter:        formatter,
+		logDataChan:      make(chan *LogData, chanSize),
+		closeChan:        make(chan struct{}),
+		flushChan:        make(chan chan struct{}),
+		closeTimeout:     closeTimeout,
+
+		overflowPolicy:     overflowPolicy,
+		overflowTimeout:    overflowTimeout,
+		dropReportInterval: dropReportInterval,
+
+		backup: newBackupConfig(config),
+	}
+	fileLogger.level.set(level)
+	log = fileLogger
+
 	return
 }
 
@@ -95,238 +219,498 @@ func (f *FileLogger) Init() {
 		os.Mkdir(f.logPath, 0755)
 	}
 
-	filename := fmt.Sprintf("%s/%s.log", f.logPath, f.logName)
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
-	if err != nil {
-		panic(fmt.Sprintf("open faile %s failed, err:%v", filename, err))
+	mainFile := f.openLogFile(f.logName + ".log")
+	f.files = []*logFile{mainFile}
+
+	//写错误日志和fatal日志的文件
+	var warnFile *logFile
+	if f.wfLevel <= LogLevelFatal {
+		warnFile = f.openLogFile(f.logName + ".log.wf")
+		f.files = append(f.files, warnFile)
 	}
 
-	f.file = file
+	// 单独一个文件的级别，文件名如 app.log.error
+	var levelFiles [LogLevelFatal + 1]*logFile
+	for _, level := range f.levelFiles {
+		levelFiles[level] = f.openLogFile(f.logName + ".log." + strings.ToLower(getLevelText(level)))
+		f.files = append(f.files, levelFiles[level])
+	}
 
-	//写错误日志和fatal日志的文件
-	filename = fmt.Sprintf("%s/%s.log.wf", f.logPath, f.logName)
-	file, err = os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
-	if err != nil {
-		panic(fmt.Sprintf("open faile %s failed, err:%v", filename, err))
+	for level := LogLevelDebug; level <= LogLevelFatal; level++ {
+		target := mainFile
+		if levelFiles[level] != nil {
+			target = levelFiles[level]
+		} else if warnFile != nil && level >= f.wfLevel {
+			target = warnFile
+		}
+
+		f.routes[level] = []*logFile{target}
+		if target != mainFile && f.wfDuplicate {
+			f.routes[level] = append(f.routes[level], mainFile)
+		}
 	}
 
-	f.warnFile = file
+	f.doneChan = make(chan struct{})
 	go f.writeLogBackground()
 }
 
-func (f *FileLogger) splitFileHour(warnFile bool) {
-	now := time.Now()
-	hour := now.Hour()
-	if hour == f.lastSplitHour {
-		return
+// openLogFile 打开一个输出文件，已有的文件继续追加
+func (f *FileLogger) openLogFile(name string) *logFile {
+	filename := fmt.Sprintf("%s/%s", f.logPath, name)
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
+	if err != nil {
+		panic(fmt.Sprintf("open faile %s failed, err:%v", filename, err))
 	}
 
-	f.lastSplitHour = hour
-	var backupFilename string
-	var filename string
+	lf := &logFile{name: name}
+	f.setFile(lf, file)
+	f.initSplitStart(lf)
+	f.cleanBackups("", lf)
+	return lf
+}
 
-	if warnFile {
-		backupFilename = fmt.Sprintf("%s/%s.log.wf_%04d%02d%02d%02d",
-			f.logPath, f.logName, now.Year(), now.Month(), now.Day(), f.lastSplitHour)
+// routeFiles 返回这个级别的日志需要写入的文件
+func (f *FileLogger) routeFiles(level int) []*logFile {
+	if level < LogLevelDebug {
+		level = LogLevelDebug
+	} else if level > LogLevelFatal {
+		level = LogLevelFatal
+	}
+	return f.routes[level]
+}
 
-		filename = fmt.Sprintf("%s/%s.log.wf", f.logPath, f.logName)
-	} else {
-		backupFilename = fmt.Sprintf("%s/%s.log_%04d%02d%02d%02d",
-			f.logPath, f.logName, now.Year(), now.Month(), now.Day(), f.lastSplitHour)
-		filename = fmt.Sprintf("%s/%s.log", f.logPath, f.logName)
+// splitPeriod 返回t所在切分周期的起始时间，按大小切分时没有周期
+func (f *FileLogger) splitPeriod(t time.Time) time.Time {
+	switch f.logSplitType {
+	case LogSplitTypeHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case LogSplitTypeDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case LogSplitTypeMinute:
+		minutes := (t.Hour()*60 + t.Minute()) / f.logSplitInterval * f.logSplitInterval
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, minutes, 0, 0, t.Location())
+	}
+	return t
+}
+
+// backupFilename 备份文件名使用文件内日志所属周期的起始时间
+func (f *FileLogger) backupFilename(lf *logFile, start time.Time) string {
+	var suffix string
+	switch f.logSplitType {
+	case LogSplitTypeHour:
+		suffix = fmt.Sprintf("%04d%02d%02d%02d", start.Year(), start.Month(), start.Day(), start.Hour())
+	case LogSplitTypeDay:
+		suffix = fmt.Sprintf("%04d%02d%02d", start.Year(), start.Month(), start.Day())
+	case LogSplitTypeMinute:
+		suffix = fmt.Sprintf("%04d%02d%02d%02d%02d",
+			start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute())
+	default:
+		suffix = fmt.Sprintf("%04d%02d%02d%02d%02d%02d",
+			start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second())
 	}
 
-	file := f.file
-	if warnFile {
-		file = f.warnFile
+	backupFilename := fmt.Sprintf("%s/%s%s", f.logPath, backupPrefix(lf), suffix)
+	// 同一个周期内按大小切分了多次，后面的文件加上序号
+	for i := 1; fileExists(backupFilename) || fileExists(backupFilename+".gz"); i++ {
+		backupFilename = fmt.Sprintf("%s/%s%s.%d", f.logPath, backupPrefix(lf), suffix, i)
 	}
+	return backupFilename
+}
 
-	file.Close()
-	os.Rename(filename, backupFilename)
+// setFile 替换当前写入的文件，文件大小之后由写入累加，不用每条日志都Stat
+func (f *FileLogger) setFile(lf *logFile, file *os.File) {
+	lf.file = file
+	lf.size = 0
+	if statInfo, err := file.Stat(); err == nil {
+		lf.size = statInfo.Size()
+	}
 
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
-	if err != nil {
+	if f.bufferSize <= 0 {
 		return
 	}
-
-	if warnFile {
-		f.warnFile = file
+	if lf.writer == nil {
+		lf.writer = bufio.NewWriterSize(file, f.bufferSize)
 	} else {
-		f.file = file
+		lf.writer.Reset(file)
+	}
+}
+
+// flushBuffers 把写缓冲里的日志写入文件
+func (f *FileLogger) flushBuffers() {
+	for _, lf := range f.files {
+		if lf.writer != nil {
+			lf.writer.Flush()
+		}
 	}
 }
 
-func (f *FileLogger) splitFileSize(warnFile bool) {
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
 
-	file := f.file
-	if warnFile {
-		file = f.warnFile
+func (f *FileLogger) rotateFile(lf *logFile, start time.Time) {
+	filename := fmt.Sprintf("%s/%s", f.logPath, lf.name)
+	backupFilename := f.backupFilename(lf, start)
+	if lf.writer != nil {
+		lf.writer.Flush()
+	}
+	lf.file.Close()
+	if err := os.Rename(filename, backupFilename); err == nil {
+		f.cleanBackups(backupFilename, lf)
 	}
 
-	statInfo, err := file.Stat()
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
 	if err != nil {
 		return
 	}
+	f.setFile(lf, file)
+}
 
-	fileSize := statInfo.Size()
-	if fileSize <= f.logSplitSize {
+// checkSplitFile 时间切分的周期变化，或者文件超过 log_split_size 时切分文件
+func (f *FileLogger) checkSplitFile(lf *logFile) {
+	now := time.Now()
+	if f.logSplitType != LogSplitTypeSize {
+		period := f.splitPeriod(now)
+		if period.Equal(lf.splitStart) {
+			// 同一个周期内
+		} else if lf.size == 0 {
+			// 空文件不需要备份
+			lf.splitStart = period
+			return
+		} else {
+			f.rotateFile(lf, lf.splitStart)
+			lf.splitStart = period
+			return
+		}
+	}
+
+	if f.logSplitSize <= 0 || lf.size <= f.logSplitSize {
 		return
 	}
 
-	var backupFilename string
-	var filename string
+	f.rotateFile(lf, lf.splitStart)
+	if f.logSplitType == LogSplitTypeSize {
+		lf.splitStart = now
+	}
+}
 
+// initSplitStart 已有的日志文件按照最后修改时间计算所属周期
+func (f *FileLogger) initSplitStart(lf *logFile) {
 	now := time.Now()
-	if warnFile {
-		backupFilename = fmt.Sprintf("%s/%s.log.wf_%04d%02d%02d%02d%02d%02d",
-			f.logPath, f.logName, now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
+	lf.splitStart = f.splitPeriod(now)
 
-		filename = fmt.Sprintf("%s/%s.log.wf", f.logPath, f.logName)
-	} else {
-		backupFilename = fmt.Sprintf("%s/%s.log_%04d%02d%02d%02d%02d%02d",
-			f.logPath, f.logName, now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
-		filename = fmt.Sprintf("%s/%s.log", f.logPath, f.logName)
+	statInfo, err := lf.file.Stat()
+	if err != nil || statInfo.Size() == 0 {
+		return
+	}
+	if f.logSplitType == LogSplitTypeSize {
+		return
 	}
+	lf.splitStart = f.splitPeriod(statInfo.ModTime())
+}
 
-	file.Close()
-	os.Rename(filename, backupFilename)
+func (f *FileLogger) writeLogBackground() {
+	defer close(f.doneChan)
 
-	file, err = os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0755)
-	if err != nil {
-		return
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// 没有写缓冲时不需要定时刷
+	var flushTick <-chan time.Time
+	if f.bufferSize > 0 {
+		flushTicker := time.NewTicker(f.flushInterval)
+		defer flushTicker.Stop()
+		flushTick = flushTicker.C
 	}
 
-	if warnFile {
-		f.warnFile = file
-	} else {
-		f.file = file
+	var seconds int
+	for {
+		select {
+		case logData := <-f.logDataChan:
+			f.writeLogData(logData)
+		case <-flushTick:
+			f.flushBuffers()
+		case <-ticker.C:
+			for _, lf := range f.files {
+				f.checkSplitFile(lf)
+			}
+
+			seconds++
+			if seconds >= f.dropReportInterval {
+				seconds = 0
+				f.reportDropped()
+			}
+		case done := <-f.flushChan:
+			f.flush()
+			close(done)
+		case <-f.closeChan:
+			f.drain()
+			return
+		}
 	}
 }
 
-func (f *FileLogger) checkSplitFile(warnFile bool) {
-	if f.logSplitType == LogSplitTypeHour {
-		f.splitFileHour(warnFile)
-		return
+func (f *FileLogger) writeLogData(logData *LogData) {
+	data := f.formatter.Format(logData)
+	for _, lf := range f.routeFiles(logData.Level) {
+		f.checkSplitFile(lf)
+		if lf.writer != nil {
+			lf.writer.Write(data)
+			if logData.Level >= LogLevelError {
+				lf.writer.Flush()
+			}
+		} else {
+			lf.file.Write(data)
+		}
+		lf.size += int64(len(data))
 	}
-
-	f.splitFileSize(warnFile)
+	releaseLogData(logData)
 }
 
-func (f *FileLogger) writeLogBackground() {
+// flush 把当前队列里的日志全部写完并刷盘
+func (f *FileLogger) flush() {
 	for {
 		select {
-		case <-time.After(time.Second):
-			f.checkSplitFile(false)
-			f.checkSplitFile(true)
 		case logData := <-f.logDataChan:
-			var file *os.File = f.file
-			if logData.WarnAndFatal {
-				file = f.warnFile
-			}
-			f.checkSplitFile(logData.WarnAndFatal)
-			fmt.Fprintf(file, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-				logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
-
-		// 如果没有日志数据，再检查关闭信号
+			f.writeLogData(logData)
 		default:
-			select {
-			case <-f.closeChan:
-				return
-			case <-time.After(time.Second):
-				f.checkSplitFile(false)
-				f.checkSplitFile(true)
-			case logData := <-f.logDataChan:
-				var file *os.File = f.file
-				if logData.WarnAndFatal {
-					file = f.warnFile
-				}
-				f.checkSplitFile(logData.WarnAndFatal)
-				fmt.Fprintf(file, "%s %s (%s:%s:%d) %s\n", logData.TimeStr,
-					logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message)
+			f.flushBuffers()
+			for _, lf := range f.files {
+				lf.file.Sync()
 			}
+			return
 		}
 	}
 }
 
-func (f *FileLogger) SetLevel(level int) {
-	if level < LogLevelDebug || level > LogLevelFatal {
-		level = LogLevelDebug
+// drain 把关闭前已经进入队列的日志全部写完，刷盘后关闭文件
+func (f *FileLogger) drain() {
+	f.flush()
+	for _, lf := range f.files {
+		lf.file.Close()
 	}
-	f.level = level
+	f.cleanWg.Wait()
 }
 
-func (f *FileLogger) Debug(format string, args ...interface{}) {
-	if f.level > LogLevelDebug {
+func (f *FileLogger) push(logData *LogData) {
+	f.closeLock.RLock()
+	defer f.closeLock.RUnlock()
+	if f.closed.Load() {
+		f.drop(logData)
+		return
+	}
+
+	// 队列剩余的空间留给WARN及以上的日志，低级别的日志提前丢弃
+	if f.overflowPolicy == LogOverflowDropLowest && logData.Level < LogLevelWarn &&
+		len(f.logDataChan) >= f.lowLevelLimit() {
+		f.drop(logData)
 		return
 	}
 
-	logData := writeLog(LogLevelDebug, format, args...)
 	select {
 	case f.logDataChan <- logData:
+		return
+	default:
+	}
+
+	switch f.overflowPolicy {
+	case LogOverflowBlock:
+		select {
+		case f.logDataChan <- logData:
+		case <-f.closeChan:
+			f.drop(logData)
+		}
+	case LogOverflowBlockTimeout:
+		timer := time.NewTimer(f.overflowTimeout)
+		defer timer.Stop()
+		select {
+		case f.logDataChan <- logData:
+		case <-timer.C:
+			f.drop(logData)
+		case <-f.closeChan:
+			f.drop(logData)
+		}
 	default:
+		f.drop(logData)
 	}
 }
 
-func (f *FileLogger) Trace(format string, args ...interface{}) {
-	if f.level > LogLevelTrace {
+// lowLevelLimit drop_lowest策略下WARN以下的日志最多占用的队列长度
+// 保留四分之一(至少1条)给WARN及以上的日志，已经入队的日志不会被调整顺序
+func (f *FileLogger) lowLevelLimit() int {
+	size := cap(f.logDataChan)
+	reserve := size / 4
+	if reserve == 0 && size > 1 {
+		reserve = 1
+	}
+	return size - reserve
+}
+
+func (f *FileLogger) drop(logData *LogData) {
+	if logData.Level >= LogLevelDebug && logData.Level <= LogLevelFatal {
+		f.dropped[logData.Level].Add(1)
+	}
+	releaseLogData(logData)
+}
+
+// Dropped 返回启动以来各级别因为队列满或已关闭而丢弃的日志条数
+func (f *FileLogger) Dropped() map[string]int64 {
+	dropped := make(map[string]int64, len(f.dropped))
+	for level := range f.dropped {
+		dropped[getLevelText(level)] = f.dropped[level].Load()
+	}
+	return dropped
+}
+
+// reportDropped 把上次统计之后新丢弃的条数写成一条WARN日志，只在后台协程调用
+func (f *FileLogger) reportDropped() {
+	var fields []Field
+	for level := range f.dropped {
+		total := f.dropped[level].Load()
+		if total == f.dropReported[level] {
+			continue
+		}
+		fields = append(fields, Field{Key: strings.ToLower(getLevelText(level)), Value: total - f.dropReported[level]})
+		f.dropReported[level] = total
+	}
+
+	if len(fields) == 0 {
 		return
 	}
-	logData := writeLog(LogLevelTrace, format, args...)
-	select {
-	case f.logDataChan <- logData:
-	default:
+
+	logData := newLogData(LogLevelWarn, "log queue overflow, records dropped", fields, "file.go", "logger.(*FileLogger).reportDropped", 0)
+	f.writeLogData(logData)
+}
+
+func (f *FileLogger) SetLevel(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		level = LogLevelDebug
 	}
+	f.level.set(level)
 }
 
-func (f *FileLogger) Info(format string, args ...interface{}) {
-	if f.level > LogLevelInfo {
+func (f *FileLogger) GetLevel() int {
+	return f.level.get()
+}
+
+func (f *FileLogger) Debug(format string, args ...interface{}) {
+	if !f.level.enabled(LogLevelDebug) {
 		return
 	}
-	logData := writeLog(LogLevelInfo, format, args...)
-	select {
-	case f.logDataChan <- logData:
-	default:
+
+	logData := writePooledLog(LogLevelDebug, format, args...)
+	f.Output(logData)
+}
+
+func (f *FileLogger) Trace(format string, args ...interface{}) {
+	if !f.level.enabled(LogLevelTrace) {
+		return
 	}
+	logData := writePooledLog(LogLevelTrace, format, args...)
+	f.Output(logData)
 }
 
-func (f *FileLogger) Warn(format string, args ...interface{}) {
-	if f.level > LogLevelWarn {
+func (f *FileLogger) Info(format string, args ...interface{}) {
+	if !f.level.enabled(LogLevelInfo) {
 		return
 	}
+	logData := writePooledLog(LogLevelInfo, format, args...)
+	f.Output(logData)
+}
 
-	logData := writeLog(LogLevelWarn, format, args...)
-	select {
-	case f.logDataChan <- logData:
-	default:
+func (f *FileLogger) Warn(format string, args ...interface{}) {
+	if !f.level.enabled(LogLevelWarn) {
+		return
 	}
+
+	logData := writePooledLog(LogLevelWarn, format, args...)
+	f.Output(logData)
 }
 
 func (f *FileLogger) Error(format string, args ...interface{}) {
-	if f.level > LogLevelError {
+	if !f.level.enabled(LogLevelError) {
 		return
 	}
 
-	logData := writeLog(LogLevelError, format, args...)
-	select {
-	case f.logDataChan <- logData:
-	default:
-	}
+	logData := writePooledLog(LogLevelError, format, args...)
+	f.Output(logData)
 }
 
 func (f *FileLogger) Fatal(format string, args ...interface{}) {
-	if f.level > LogLevelFatal {
+	if !f.level.enabled(LogLevelFatal) {
 		return
 	}
 
-	logData := writeLog(LogLevelFatal, format, args...)
+	logData := writePooledLog(LogLevelFatal, format, args...)
+	f.Output(logData)
+}
+
+func (f *FileLogger) Logw(level int, msg string, fields ...Field) {
+	if !f.level.enabled(level) {
+		return
+	}
+
+	logData := writePooledLogw(level, msg, fields)
+	f.Output(logData)
+}
+
+func (f *FileLogger) Output(logData *LogData) {
+	if !f.level.allow(logData) || !sample(logData, f.push) {
+		releaseLogData(logData)
+		return
+	}
+
+	f.push(logData)
+}
+
+// Flush 等待调用之前进入队列的日志全部落盘
+func (f *FileLogger) Flush() {
+	flushSampling()
+	if f.doneChan == nil {
+		return
+	}
+
+	done := make(chan struct{})
 	select {
-	case f.logDataChan <- logData:
-	default:
+	case f.flushChan <- done:
+		<-done
+	case <-f.doneChan:
 	}
 }
 
 func (f *FileLogger) Close() {
-	f.file.Close()
-	f.warnFile.Close()
-	f.closeChan <- struct{}{}
+	f.CloseTimeout(f.closeTimeout)
+}
+
+// CloseTimeout 停止接收新日志，等待队列中的日志全部落盘后返回
+// timeout <= 0 时一直等待，超时返回错误，剩余日志仍会在后台继续写完
+func (f *FileLogger) CloseTimeout(timeout time.Duration) error {
+	flushSampling()
+	// 没有Init过，后台协程没有启动
+	if f.doneChan == nil {
+		return nil
+	}
+
+	f.closeOnce.Do(func() {
+		f.closed.Store(true)
+		// 等正在入队的日志完成后再通知后台协程，之后入队的日志都计入丢弃
+		go func() {
+			f.closeLock.Lock()
+			close(f.closeChan)
+			f.closeLock.Unlock()
+		}()
+	})
+
+	if timeout <= 0 {
+		<-f.doneChan
+		return nil
+	}
+
+	select {
+	case <-f.doneChan:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("close file logger timeout after %v", timeout)
+	}
 }
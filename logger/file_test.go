@@ -0,0 +1,337 @@
+package logger_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// newBenchFileLogger 创建写到临时目录的FileLogger，队列满时阻塞，保证每条日志都写入文件
+func newBenchFileLogger(b *testing.B, config map[string]string) logger.LogInterface {
+	b.Helper()
+	cfg := map[string]string{
+		"log_path":            b.TempDir(),
+		"log_name":            "bench",
+		"log_level":           "debug",
+		"log_overflow_policy": "block",
+		"log_wf_level":        "none",
+	}
+	for k, v := range config {
+		cfg[k] = v
+	}
+
+	log, err := logger.NewFileLogger(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	log.Init()
+	return log
+}
+
+// benchmarkFileLogger 计时包含Close，队列里的日志写完才算结束
+func benchmarkFileLogger(b *testing.B, config map[string]string) {
+	log := newBenchFileLogger(b, config)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("user login, uid:%d name:%s", i, "bob")
+	}
+	log.Close()
+}
+
+func BenchmarkFileLoggerUnbuffered(b *testing.B) {
+	benchmarkFileLogger(b, map[string]string{"log_write_buffer": "0"})
+}
+
+func BenchmarkFileLoggerBuffered(b *testing.B) {
+	benchmarkFileLogger(b, nil)
+}
+
+func BenchmarkFileLoggerJSON(b *testing.B) {
+	benchmarkFileLogger(b, map[string]string{"log_format": "json"})
+}
+
+func BenchmarkFileLoggerFields(b *testing.B) {
+	log := newBenchFileLogger(b, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Logw(logger.LogLevelInfo, "user login", logger.Field{Key: "uid", Value: i}, logger.Field{Key: "name", Value: "bob"})
+	}
+	log.Close()
+}
+
+func BenchmarkFileLoggerParallel(b *testing.B) {
+	log := newBenchFileLogger(b, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			log.Info("user login, uid:%d name:%s", i, "bob")
+			i++
+		}
+	})
+	log.Close()
+}
+
+// 级别不够的日志直接返回，只剩调用方参数装箱的开销
+func BenchmarkFileLoggerDisabled(b *testing.B) {
+	log := newBenchFileLogger(b, map[string]string{"log_level": "error"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("user login, uid:%d name:%s", i, "bob")
+	}
+	log.Close()
+}
+
+// installFileLogger 把FileLogger和loggertest一起设置为全局日志
+// loggertest记录的是通过了级别和采样的日志，用来核对文件里写了什么、丢了什么
+func installFileLogger(t *testing.T, config map[string]string) (string, *loggertest.Logger) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := map[string]string{
+		"log_path":  dir,
+		"log_name":  "test",
+		"log_level": "debug",
+	}
+	for k, v := range config {
+		cfg[k] = v
+	}
+
+	file, err := logger.NewFileLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Init()
+
+	rec := loggertest.New()
+	multi := &logger.MultiLogger{}
+	multi.AddSink(file)
+	multi.AddSink(rec)
+	old := logger.SetLogger(multi)
+	t.Cleanup(func() {
+		multi.Close()
+		logger.SetLogger(old)
+	})
+	return dir, rec
+}
+
+// readLines 读取日志文件，忽略日志实例自己写的丢弃统计
+func readLines(t *testing.T, filename string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.Contains(line, "log queue overflow") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestFileLoggerCloseDrains(t *testing.T) {
+	dir, rec := installFileLogger(t, map[string]string{
+		"log_chan_size":       "100000",
+		"log_overflow_policy": "block",
+		"log_wf_level":        "none",
+	})
+
+	for i := 0; i < 5000; i++ {
+		logger.Info("drain %d", i)
+	}
+	logger.CloseLogger()
+
+	lines := readLines(t, filepath.Join(dir, "test.log"))
+	if len(lines) != len(rec.Entries()) || len(lines) != 5000 {
+		t.Fatalf("file has %d lines, logged %d, want 5000", len(lines), len(rec.Entries()))
+	}
+	if !strings.HasSuffix(lines[len(lines)-1], "drain 4999") {
+		t.Errorf("last line = %q", lines[len(lines)-1])
+	}
+}
+
+// countLevels 按级别统计文件里的行数
+func countLevels(lines []string) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, line := range lines {
+		for level := logger.LogLevelDebug; level <= logger.LogLevelFatal; level++ {
+			if strings.Contains(line, " "+logger.LevelText(level)+" ") {
+				counts[logger.LevelText(level)]++
+				break
+			}
+		}
+	}
+	return counts
+}
+
+func TestFileLoggerOverflowAccounting(t *testing.T) {
+	for _, policy := range []string{"drop", "drop_lowest"} {
+		t.Run(policy, func(t *testing.T) {
+			dir, rec := installFileLogger(t, map[string]string{
+				"log_chan_size":       "8",
+				"log_overflow_policy": policy,
+				"log_wf_level":        "none",
+				"log_write_buffer":    "0",
+			})
+
+			for i := 0; i < 2000; i++ {
+				logger.Error("overflow %d", i)
+				logger.Info("overflow %d", i)
+			}
+			logger.CloseLogger()
+			lines := readLines(t, filepath.Join(dir, "test.log"))
+
+			// 每条日志要么写入文件，要么计入丢弃
+			written := countLevels(lines)
+			dropped := logger.Dropped()
+			for _, level := range []int{logger.LogLevelInfo, logger.LogLevelError} {
+				name := logger.LevelText(level)
+				var logged int64
+				for _, entry := range rec.Entries() {
+					if entry.Level == level {
+						logged++
+					}
+				}
+				if written[name]+dropped[name] != logged {
+					t.Errorf("%s: written %d + dropped %d != logged %d", name, written[name], dropped[name], logged)
+				}
+			}
+			if policy == "drop_lowest" && dropped["ERROR"] > 0 && dropped["INFO"] == 0 {
+				t.Errorf("drop_lowest dropped ERROR before INFO: %v", dropped)
+			}
+
+			// 丢弃日志不会改变写入的顺序
+			last := -1
+			for _, line := range lines {
+				if !strings.Contains(line, " ERROR ") {
+					continue
+				}
+				var n int
+				fmt.Sscanf(line[strings.LastIndex(line, " ")+1:], "%d", &n)
+				if n <= last {
+					t.Fatalf("ERROR %d written after %d", n, last)
+				}
+				last = n
+			}
+		})
+	}
+}
+
+// 关闭时还在打日志，每条日志同样要么写入文件，要么计入丢弃
+func TestFileLoggerCloseWhileLogging(t *testing.T) {
+	dir, rec := installFileLogger(t, map[string]string{
+		"log_chan_size": "64",
+		"log_wf_level":  "none",
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				logger.Info("closing %d", i)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	logger.CloseLogger()
+	wg.Wait()
+
+	written := int64(len(readLines(t, filepath.Join(dir, "test.log"))))
+	dropped := logger.Dropped()["INFO"]
+	if logged := int64(len(rec.Entries())); written+dropped != logged {
+		t.Errorf("written %d + dropped %d != logged %d", written, dropped, logged)
+	}
+}
+
+func TestFileLoggerRotationNaming(t *testing.T) {
+	dir, rec := installFileLogger(t, map[string]string{
+		"log_split_type":   "size",
+		"log_split_size":   "300",
+		"log_wf_level":     "none",
+		"log_write_buffer": "0",
+	})
+
+	for i := 0; i < 30; i++ {
+		logger.Info("rotate %d", i)
+	}
+	logger.CloseLogger()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupName := regexp.MustCompile(`^test\.log_\d{14}(\.\d+)?$`)
+	var backups, total int
+	for _, entry := range entries {
+		if entry.Name() != "test.log" {
+			if !backupName.MatchString(entry.Name()) {
+				t.Errorf("unexpected backup name %q", entry.Name())
+			}
+			backups++
+		}
+		total += len(readLines(t, filepath.Join(dir, entry.Name())))
+	}
+	if backups == 0 {
+		t.Errorf("no backups created, files: %v", entries)
+	}
+	if total != len(rec.Entries()) {
+		t.Errorf("files have %d lines, logged %d", total, len(rec.Entries()))
+	}
+}
+
+func TestFileLoggerRouting(t *testing.T) {
+	dir, rec := installFileLogger(t, map[string]string{
+		"log_wf_level":     "warn",
+		"log_level_files":  "fatal",
+		"log_wf_duplicate": "true",
+	})
+	fatalExit := logger.SetFatalExit(false)
+	t.Cleanup(func() { logger.SetFatalExit(fatalExit) })
+
+	logger.Debug("route debug")
+	logger.Info("route info")
+	logger.Warn("route warn")
+	logger.Error("route error")
+	logger.Fatal("route fatal")
+	logger.CloseLogger()
+
+	for _, level := range []int{logger.LogLevelDebug, logger.LogLevelInfo, logger.LogLevelWarn, logger.LogLevelError, logger.LogLevelFatal} {
+		rec.AssertLogged(t, level, "route "+strings.ToLower(logger.LevelText(level)))
+	}
+
+	want := map[string][]string{
+		// 开启了 log_wf_duplicate，单独写文件的级别也会写一份到主文件
+		"test.log":       {"route debug", "route info", "route warn", "route error", "route fatal"},
+		"test.log.wf":    {"route warn", "route error"},
+		"test.log.fatal": {"route fatal"},
+	}
+	for name, msgs := range want {
+		lines := readLines(t, filepath.Join(dir, name))
+		if len(lines) != len(msgs) {
+			t.Errorf("%s has %d lines, want %d: %q", name, len(lines), len(msgs), lines)
+			continue
+		}
+		for i, msg := range msgs {
+			if !strings.HasSuffix(lines[i], msg) {
+				t.Errorf("%s line %d = %q, want %q", name, i, lines[i], msg)
+			}
+		}
+	}
+}
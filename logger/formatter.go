@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LOGFORMAT_TEXT = "text"
+	LOGFORMAT_JSON = "json"
+	LOGFORMAT_DEV  = "dev"
+	LOGFORMAT_MSG  = "msg"
+)
+
+// Formatter 把一条日志渲染成一行输出（包含结尾的换行符）
+type Formatter interface {
+	Format(logData *LogData) []byte
+}
+
+var (
+	formatterLock sync.RWMutex
+	formatters    = map[string]Formatter{
+		LOGFORMAT_TEXT: &TextFormatter{},
+		LOGFORMAT_JSON: &JsonFormatter{},
+		LOGFORMAT_DEV:  &DevFormatter{},
+		LOGFORMAT_MSG:  &MessageFormatter{},
+	}
+)
+
+// RegisterFormatter 注册自定义的日志格式，之后可以通过 log_format 配置使用
+func RegisterFormatter(name string, formatter Formatter) {
+	formatterLock.Lock()
+	defer formatterLock.Unlock()
+	formatters[name] = formatter
+}
+
+func newFormatter(config map[string]string) (Formatter, error) {
+	name, ok := config["log_format"]
+	if !ok || name == "" {
+		name = LOGFORMAT_TEXT
+	}
+
+	formatterLock.RLock()
+	defer formatterLock.RUnlock()
+	formatter, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupport log_format:%s", name)
+	}
+	return formatter, nil
+}
+
+// TextFormatter 2006-01-02 15:04:05.999 DEBUG (file.go:pkg.Func:29) message k1=v1
+type TextFormatter struct{}
+
+func (t *TextFormatter) Format(logData *LogData) []byte {
+	var line string
+	// 关闭了调用位置的获取
+	if logData.Filename == "" {
+		line = fmt.Sprintf("%s %s %s%s\n", logData.TimeStr,
+			logData.LevelStr, logData.Message, fieldsText(logData.Fields))
+	} else {
+		line = fmt.Sprintf("%s %s (%s:%s:%d) %s%s\n", logData.TimeStr,
+			logData.LevelStr, logData.Filename, logData.FuncName, logData.LineNo, logData.Message, fieldsText(logData.Fields))
+	}
+
+	// 调用栈跟在日志后面，每行缩进一个tab
+	if logData.Stack != "" {
+		line += "\t" + strings.ReplaceAll(logData.Stack, "\n", "\n\t") + "\n"
+	}
+	return []byte(line)
+}
+
+// MessageFormatter 只输出消息和字段，时间和级别由外层协议(如syslog)携带
+// message k1=v1
+type MessageFormatter struct{}
+
+func (m *MessageFormatter) Format(logData *LogData) []byte {
+	line := logData.Message + fieldsText(logData.Fields) + "\n"
+	if logData.Stack != "" {
+		line += "\t" + strings.ReplaceAll(logData.Stack, "\n", "\n\t") + "\n"
+	}
+	return []byte(line)
+}
+
+// DevFormatter 本地开发用的紧凑格式，只保留时分秒和文件名
+// 15:04:05.000 DEBUG file.go:29 message k1=v1
+type DevFormatter struct{}
+
+func (d *DevFormatter) Format(logData *LogData) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(logData.Time.Format("15:04:05.000"))
+	buf.WriteByte(' ')
+	// LevelStr可能带了颜色，按级别本身的长度对齐
+	buf.WriteString(logData.LevelStr)
+	for i := len(getLevelText(logData.Level)); i < 5; i++ {
+		buf.WriteByte(' ')
+	}
+	if logData.Filename != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(path.Base(logData.Filename))
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(logData.LineNo))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(logData.Message)
+	buf.WriteString(fieldsText(logData.Fields))
+	buf.WriteByte('\n')
+	if logData.Stack != "" {
+		buf.WriteString("\t" + strings.ReplaceAll(logData.Stack, "\n", "\n\t") + "\n")
+	}
+	return buf.Bytes()
+}
+
+// JsonFormatter 每条日志输出一个JSON对象，结构化字段平铺在顶层
+type JsonFormatter struct{}
+
+var jsonReservedKeys = map[string]bool{
+	"time":    true,
+	"level":   true,
+	"file":    true,
+	"func":    true,
+	"line":    true,
+	"message": true,
+	"stack":   true,
+}
+
+func (j *JsonFormatter) Format(logData *LogData) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJsonString(&buf, "time")
+	buf.WriteByte(':')
+	writeJsonString(&buf, logData.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJsonString(&buf, logData.LevelStr)
+	if logData.Filename != "" {
+		buf.WriteString(`,"file":`)
+		writeJsonString(&buf, logData.Filename)
+		buf.WriteString(`,"func":`)
+		writeJsonString(&buf, logData.FuncName)
+		buf.WriteString(`,"line":`)
+		buf.WriteString(strconv.Itoa(logData.LineNo))
+	}
+	buf.WriteString(`,"message":`)
+	writeJsonString(&buf, logData.Message)
+	if logData.Stack != "" {
+		buf.WriteString(`,"stack":`)
+		writeJsonString(&buf, logData.Stack)
+	}
+
+	for _, field := range logData.Fields {
+		key := field.Key
+		// 字段名和固定字段冲突时加前缀，避免同一个对象出现重复的key
+		if jsonReservedKeys[key] {
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		writeJsonString(&buf, key)
+		buf.WriteByte(':')
+		writeJsonValue(&buf, field.Value)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func writeJsonString(buf *bytes.Buffer, str string) {
+	data, _ := json.Marshal(str)
+	buf.Write(data)
+}
+
+func writeJsonValue(buf *bytes.Buffer, value interface{}) {
+	if err, ok := value.(error); ok {
+		writeJsonString(buf, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		writeJsonString(buf, fmt.Sprintf("%v", value))
+		return
+	}
+	buf.Write(data)
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// logLevel 并发安全的日志级别，运行时可以随时修改
+type logLevel struct {
+	level atomic.Int32
+}
+
+func (l *logLevel) set(level int) {
+	l.level.Store(int32(level))
+}
+
+func (l *logLevel) get() int {
+	return int(l.level.Load())
+}
+
+// enabled 判断是否需要构造这条日志
+// 达到按包覆盖的最低级别时，需要先取到调用位置才能判断，这里先放行
+func (l *logLevel) enabled(level int) bool {
+	return level >= l.get() || overrideEnabled(level)
+}
+
+// allow 日志构造完成后的最终判断，命中覆盖规则时使用规则里的级别
+func (l *logLevel) allow(logData *LogData) bool {
+	if level, ok := overrideLevel(logData); ok {
+		return logData.Level >= level
+	}
+	return logData.Level >= l.get()
+}
+
+// logEnabled 按当前日志实例的级别判断是否需要构造日志
+func logEnabled(level int) bool {
+	return level >= getLog().GetLevel() || overrideEnabled(level)
+}
+
+// overrideEnabled 是否可能有覆盖规则放行这个级别
+func overrideEnabled(level int) bool {
+	overrides := levelOverrides.Load()
+	return overrides != nil && level >= overrides.minLevel
+}
+
+// LevelText 返回级别的名字，如 DEBUG、ERROR
+func LevelText(level int) string {
+	return getLevelText(level)
+}
+
+// ParseLevel 把 debug/trace/info/warn/error/fatal 转换成日志级别
+func ParseLevel(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "trace":
+		return LogLevelTrace, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	case "fatal":
+		return LogLevelFatal, nil
+	}
+	return LogLevelDebug, fmt.Errorf("unknown log level:%s", name)
+}
+
+type levelOverride struct {
+	pattern string
+	level   int
+}
+
+// overrideRules 按规则长度从长到短排列，minLevel 是所有规则里最低的级别
+type overrideRules struct {
+	rules    []levelOverride
+	minLevel int
+}
+
+var (
+	overrideLock sync.Mutex
+	// 为nil表示没有覆盖规则，读取时不加锁
+	levelOverrides atomic.Pointer[overrideRules]
+)
+
+// storeOverrides 需要持有overrideLock
+func storeOverrides(overrides []levelOverride) {
+	if len(overrides) == 0 {
+		levelOverrides.Store(nil)
+		return
+	}
+
+	rules := &overrideRules{rules: overrides, minLevel: LogLevelFatal}
+	for _, override := range overrides {
+		if override.level < rules.minLevel {
+			rules.minLevel = override.level
+		}
+	}
+	levelOverrides.Store(rules)
+}
+
+// SetPackageLevel 为某个包、函数或文件单独设置日志级别
+// pattern 可以是文件名 "tcp_server.go"，包名 "tcp"，或者函数名 "tcp.(*TcpServer).handleWrite"
+// 多个规则都匹配时，使用最长的规则
+// 规则按调用位置匹配，EnableCaller(false) 关闭调用位置后不生效
+// MultiLogger 里单独配置了 "<sink>.log_level" 的输出仍然按自己的级别过滤
+func SetPackageLevel(pattern string, level int) {
+	overrideLock.Lock()
+	defer overrideLock.Unlock()
+
+	overrides := make([]levelOverride, 0)
+	if old := levelOverrides.Load(); old != nil {
+		for _, override := range old.rules {
+			if override.pattern != pattern {
+				overrides = append(overrides, override)
+			}
+		}
+	}
+	overrides = append(overrides, levelOverride{pattern: pattern, level: level})
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return len(overrides[i].pattern) > len(overrides[j].pattern)
+	})
+	storeOverrides(overrides)
+}
+
+// RemovePackageLevel 删除SetPackageLevel设置的规则
+func RemovePackageLevel(pattern string) {
+	overrideLock.Lock()
+	defer overrideLock.Unlock()
+
+	old := levelOverrides.Load()
+	if old == nil {
+		return
+	}
+
+	overrides := make([]levelOverride, 0, len(old.rules))
+	for _, override := range old.rules {
+		if override.pattern != pattern {
+			overrides = append(overrides, override)
+		}
+	}
+	storeOverrides(overrides)
+}
+
+// ClearPackageLevels 删除所有按包设置的级别
+func ClearPackageLevels() {
+	overrideLock.Lock()
+	defer overrideLock.Unlock()
+	levelOverrides.Store(nil)
+}
+
+// PackageLevels 返回当前所有按包设置的级别
+func PackageLevels() map[string]int {
+	levels := make(map[string]int)
+	if overrides := levelOverrides.Load(); overrides != nil {
+		for _, override := range overrides.rules {
+			levels[override.pattern] = override.level
+		}
+	}
+	return levels
+}
+
+func overrideLevel(logData *LogData) (int, bool) {
+	overrides := levelOverrides.Load()
+	if overrides == nil {
+		return 0, false
+	}
+
+	for _, override := range overrides.rules {
+		if override.pattern == path.Base(logData.Filename) ||
+			override.pattern == logData.FuncName ||
+			strings.HasPrefix(logData.FuncName, override.pattern+".") {
+			return override.level, true
+		}
+	}
+	return 0, false
+}
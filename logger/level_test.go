@@ -0,0 +1,51 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// installLevelGate loggertest 本身不处理覆盖规则，由MultiLogger按info级别和覆盖规则过滤
+func installLevelGate(t testing.TB) *loggertest.Logger {
+	rec := loggertest.New()
+	multi := &logger.MultiLogger{}
+	multi.AddSink(rec)
+	multi.SetLevel(logger.LogLevelInfo)
+	rec.SetLevel(logger.LogLevelDebug)
+	old := logger.SetLogger(multi)
+	t.Cleanup(func() {
+		logger.ClearPackageLevels()
+		logger.SetLogger(old)
+	})
+	return rec
+}
+
+func TestPackageLevel(t *testing.T) {
+	rec := installLevelGate(t)
+
+	// 其它文件的规则不影响这里
+	logger.SetPackageLevel("other.go", logger.LogLevelDebug)
+	logger.Debug("other rule")
+	rec.AssertNotLogged(t, logger.LogLevelDebug, "other rule")
+
+	logger.SetPackageLevel("level_test.go", logger.LogLevelDebug)
+	logger.Debug("file rule")
+	rec.AssertLogged(t, logger.LogLevelDebug, "file rule")
+
+	logger.SetPackageLevel("level_test.go", logger.LogLevelError)
+	logger.Warn("raised")
+	rec.AssertNotLogged(t, logger.LogLevelWarn, "raised")
+}
+
+// 覆盖规则的级别都高于Debug时，Debug日志不需要取调用位置
+func BenchmarkDebugWithWarnOverride(b *testing.B) {
+	installLevelGate(b)
+	logger.SetPackageLevel("other.go", logger.LogLevelWarn)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("disabled")
+	}
+}
@@ -3,12 +3,19 @@ package logger
 type LogInterface interface {
 	Init()
 	SetLevel(level int)
+	GetLevel() int
 	Debug(format string, args ...interface{})
 	Trace(format string, args ...interface{})
 	Info(format string, args ...interface{})
 	Warn(format string, args ...interface{})
 	Error(format string, args ...interface{})
 	Fatal(format string, args ...interface{})
+	// Logw 输出不做格式化的消息，并携带结构化字段
+	Logw(level int, msg string, fields ...Field)
+	// Output 输出一条已经构造好的日志，级别不够时忽略，用于多路输出等包装场景
+	Output(logData *LogData)
+	// Flush 阻塞直到已经写入的日志全部落盘
+	Flush()
 	Close()
 }
  
\ No newline at end of file
@@ -1,12 +1,36 @@
 package logger
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 当前的日志实例，通过 getLog 读取，InitLogger 等函数整体替换
+var (
+	currentLog atomic.Pointer[logHolder]
+	replaceLog sync.Mutex // 串行化替换日志实例的操作，AddLogger需要先读再写
+)
+
+type logHolder struct {
+	log LogInterface
+}
 
-var log LogInterface
+var (
+	// Fatal 之后是否退出进程，配置 log_fatal_exit=false 可以关闭
+	fatalExit atomic.Bool
+	exitLock  sync.Mutex
+	exitHooks []func()
+)
 
 const (
 	LOGTYPE_FILE    = "file"
 	LOGTYPE_CONSOLE = "console"
+	LOGTYPE_MULTI   = "multi"
+	LOGTYPE_SYSLOG  = "syslog"
+	LOGTYPE_NET     = "net"
 )
 
 /*
@@ -23,52 +47,223 @@ console, "初始化console日志实例"
 // 	return
 // }
 
+func getLog() LogInterface {
+	return currentLog.Load().log
+}
+
+func setLog(l LogInterface) LogInterface {
+	if l == nil {
+		l = newDefaultLogger()
+	}
+	old := currentLog.Swap(&logHolder{log: l})
+	if old == nil {
+		return nil
+	}
+	return old.log
+}
+
+// newDefaultLogger 没有调用 InitLogger 时使用的日志，info级别输出到控制台
+func newDefaultLogger() LogInterface {
+	l, _ := NewConsoleLogger(map[string]string{"log_level": "info"})
+	return l
+}
+
 func CloseLogger() {
-	if log != nil {
-		log.Close()
+	getLog().Close()
+}
+
+// CloseLoggerTimeout 关闭日志并等待缓冲的日志写完，超时返回错误
+func CloseLoggerTimeout(timeout time.Duration) error {
+	l := getLog()
+	if closer, ok := l.(interface {
+		CloseTimeout(timeout time.Duration) error
+	}); ok {
+		return closer.CloseTimeout(timeout)
 	}
+	l.Close()
+	return nil
 }
 
-func InitLogger(name string, config map[string]string) (err error) {
+// Dropped 返回当前日志实例丢弃的日志条数，不支持统计的实例返回nil
+func Dropped() map[string]int64 {
+	if counter, ok := getLog().(interface {
+		Dropped() map[string]int64
+	}); ok {
+		return counter.Dropped()
+	}
+	return nil
+}
+
+func init() {
+	fatalExit.Store(true)
+	setLog(newDefaultLogger())
+}
+
+// SetFatalExit 设置Fatal之后是否退出进程，返回之前的设置
+func SetFatalExit(exit bool) bool {
+	return fatalExit.Swap(exit)
+}
+
+// RegisterExitHook 注册Fatal退出进程前执行的回调，按注册顺序执行
+func RegisterExitHook(hook func()) {
+	exitLock.Lock()
+	defer exitLock.Unlock()
+	exitHooks = append(exitHooks, hook)
+}
+
+// exitOnFatal 刷新日志，执行退出回调，然后退出进程
+func exitOnFatal() {
+	if !fatalExit.Load() {
+		return
+	}
+
+	getLog().Flush()
+
+	exitLock.Lock()
+	hooks := exitHooks
+	exitLock.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+	os.Exit(1)
+}
+
+func newLogger(name string, config map[string]string) (LogInterface, error) {
 	switch name {
 	case LOGTYPE_FILE:
-		log, err = NewFileLogger(config)
-		if err != nil {
-			return err
-		}
-		log.Init()
+		return NewFileLogger(config)
 	case LOGTYPE_CONSOLE:
-		log, err = NewConsoleLogger(config)
-		if err != nil {
-			return err
-		}
-	default:
-		err = fmt.Errorf("unsupport logger name:%s", name)
+		return NewConsoleLogger(config)
+	case LOGTYPE_MULTI:
+		return NewMultiLogger(config)
+	case LOGTYPE_SYSLOG:
+		return NewSyslogLogger(config)
+	case LOGTYPE_NET:
+		return NewNetLogger(config)
 	}
+	return nil, fmt.Errorf("unsupport logger name:%s", name)
+}
+
+// InitLogger 按配置创建日志实例并替换当前实例，之前的实例会被关闭
+// 配置错误时返回错误，当前的实例和全局设置都保持不变
+func InitLogger(name string, config map[string]string) (err error) {
+	stack, err := parseStackLevel(config)
+	if err != nil {
+		return err
+	}
+	l, err := newLogger(name, config)
+	if err != nil {
+		return err
+	}
+
+	fatalExit.Store(config["log_fatal_exit"] != "false")
+	configureSampling(config)
+	configureCaller(config)
+	SetStackLevel(stack)
+	l.Init()
 
+	replaceLog.Lock()
+	old := setLog(l)
+	replaceLog.Unlock()
+
+	// 写完之前实例里缓冲的日志，释放文件和连接
+	if old != nil {
+		old.Close()
+	}
 	return
 }
 
+// SetLogger 直接替换当前的日志实例，返回之前的实例
+// 用于 NewSlogLogger 这类不能通过配置创建的实例，传nil恢复默认的控制台日志
+func SetLogger(l LogInterface) LogInterface {
+	replaceLog.Lock()
+	defer replaceLog.Unlock()
+	return setLog(l)
+}
+
+// AddLogger 在当前日志实例之外再增加一路输出
+// 当前实例不是multi时，会把它和新实例一起包装成一个MultiLogger
+func AddLogger(name string, config map[string]string) error {
+	l, err := newLogger(name, config)
+	if err != nil {
+		return err
+	}
+	l.Init()
+
+	replaceLog.Lock()
+	defer replaceLog.Unlock()
+	current := getLog()
+	// 新的输出使用config里自己的级别，不跟随 SetLevel
+	if multi, ok := current.(*MultiLogger); ok {
+		multi.AddSink(l)
+		multi.setOwnLevel(l)
+		return nil
+	}
+
+	multi := &MultiLogger{}
+	multi.AddSink(current)
+	multi.AddSink(l)
+	multi.setOwnLevel(l)
+	setLog(multi)
+	return nil
+}
+
+// SetLevel 运行时修改当前日志实例的级别，可以并发调用
+func SetLevel(level int) {
+	getLog().SetLevel(level)
+}
+
+func GetLevel() int {
+	return getLog().GetLevel()
+}
+
 func Debug(format string, args ...interface{}) {
-	log.Debug(format, args...)
+	getLog().Debug(format, args...)
 }
 
 func Trace(format string, args ...interface{}) {
-	log.Trace(format, args...)
+	getLog().Trace(format, args...)
 }
 
 func Info(format string, args ...interface{}) {
-	log.Info(format, args...)
+	getLog().Info(format, args...)
 }
 
 func Warn(format string, args ...interface{}) {
-	log.Warn(format, args...)
+	getLog().Warn(format, args...)
 }
 
 func Error(format string, args ...interface{}) {
-	log.Error(format, args...)
+	getLog().Error(format, args...)
 }
 
+// Fatal 记录日志后刷新所有日志，执行退出回调并以状态码1退出进程
 func Fatal(format string, args ...interface{}) {
-	log.Fatal(format, args...)
+	getLog().Fatal(format, args...)
+	exitOnFatal()
+}
+
+func Debugw(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelDebug, msg, toFields(kv)...)
+}
+
+func Tracew(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelTrace, msg, toFields(kv)...)
+}
+
+func Infow(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelInfo, msg, toFields(kv)...)
+}
+
+func Warnw(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelWarn, msg, toFields(kv)...)
+}
+
+func Errorw(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelError, msg, toFields(kv)...)
+}
+
+func Fatalw(msg string, kv ...interface{}) {
+	getLog().Logw(LogLevelFatal, msg, toFields(kv)...)
+	exitOnFatal()
 }
@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// 重新初始化时关闭之前的实例，队列里的日志写入文件
+func TestInitLoggerClosesPrevious(t *testing.T) {
+	old := logger.SetLogger(nil)
+	t.Cleanup(func() {
+		logger.CloseLogger()
+		logger.SetLogger(old)
+	})
+
+	dir := t.TempDir()
+	config := map[string]string{
+		"log_path":     dir,
+		"log_name":     "first",
+		"log_level":    "debug",
+		"log_wf_level": "none",
+	}
+	if err := logger.InitLogger("file", config); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		logger.Info("first %d", i)
+	}
+
+	config["log_name"] = "second"
+	if err := logger.InitLogger("file", config); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "first.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 1000 {
+		t.Errorf("first.log has %d lines after re-init, want 1000", n)
+	}
+}
+
+// 配置错误时不修改当前实例和全局设置
+func TestInitLoggerInvalidConfig(t *testing.T) {
+	// loggertest 本身不采样，放在MultiLogger后面才能看出采样设置是否被修改
+	rec := loggertest.New()
+	multi := &logger.MultiLogger{}
+	multi.AddSink(rec)
+	old := logger.SetLogger(multi)
+	t.Cleanup(func() { logger.SetLogger(old) })
+
+	err := logger.InitLogger("file", map[string]string{
+		"log_level":          "debug",
+		"log_caller":         "false",
+		"log_sample_initial": "1",
+	})
+	if err == nil {
+		t.Fatal("InitLogger without log_path succeeded")
+	}
+
+	for i := 0; i < 3; i++ {
+		logger.Info("still here")
+	}
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("logged %d entries, want 3 (sampling changed by a rejected config)", len(entries))
+	}
+	if entries[0].Filename == "" {
+		t.Error("caller info disabled by a rejected config")
+	}
+}
@@ -0,0 +1,169 @@
+// Package loggertest 提供测试用的内存日志实例
+//
+//	func TestLogin(t *testing.T) {
+//		l := loggertest.Install(t)
+//		Login("bob")
+//		l.AssertLogged(t, logger.LogLevelInfo, "login success")
+//	}
+package loggertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+)
+
+// Logger 把日志记录在内存里的 LogInterface，不做采样，Fatal 不退出进程
+type Logger struct {
+	lock    sync.Mutex
+	level   int
+	entries []logger.LogData
+}
+
+// New 创建一个记录所有级别日志的实例
+func New() *Logger {
+	return &Logger{level: logger.LogLevelDebug}
+}
+
+// Install 创建一个实例替换当前的全局日志，测试结束时恢复原来的日志
+// 测试期间 Fatal 不会退出进程
+func Install(t testing.TB) *Logger {
+	l := New()
+	old := logger.SetLogger(l)
+	fatalExit := logger.SetFatalExit(false)
+	t.Cleanup(func() {
+		logger.SetLogger(old)
+		logger.SetFatalExit(fatalExit)
+	})
+	return l
+}
+
+func (l *Logger) Init() {
+
+}
+
+func (l *Logger) SetLevel(level int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.level = level
+}
+
+func (l *Logger) GetLevel() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.level
+}
+
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.log(logger.LogLevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.log(logger.LogLevelTrace, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.log(logger.LogLevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.log(logger.LogLevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.log(logger.LogLevelError, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Fatal(format string, args ...interface{}) {
+	l.log(logger.LogLevelFatal, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Logw(level int, msg string, fields ...logger.Field) {
+	l.log(level, msg, fields)
+}
+
+func (l *Logger) log(level int, msg string, fields []logger.Field) {
+	if level < l.GetLevel() {
+		return
+	}
+	l.Output(logger.NewLogData(level, msg, fields))
+}
+
+// Output 保存一份日志的副本，LogData可能被其它输出继续使用
+func (l *Logger) Output(logData *logger.LogData) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if logData.Level < l.level {
+		return
+	}
+	l.entries = append(l.entries, *logData)
+}
+
+func (l *Logger) Flush() {
+
+}
+
+func (l *Logger) Close() {
+
+}
+
+// Entries 返回记录的所有日志
+func (l *Logger) Entries() []logger.LogData {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	entries := make([]logger.LogData, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// Reset 清空记录的日志
+func (l *Logger) Reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = nil
+}
+
+// Logged 是否有指定级别并且消息包含substr的日志
+func (l *Logger) Logged(level int, substr string) bool {
+	for _, entry := range l.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged 没有指定级别并且消息包含substr的日志时测试失败
+func (l *Logger) AssertLogged(t testing.TB, level int, substr string) {
+	t.Helper()
+	if !l.Logged(level, substr) {
+		t.Errorf("expected %s log containing %q, got:\n%s", logger.LevelText(level), substr, l.dump())
+	}
+}
+
+// AssertNotLogged 有指定级别并且消息包含substr的日志时测试失败
+func (l *Logger) AssertNotLogged(t testing.TB, level int, substr string) {
+	t.Helper()
+	if l.Logged(level, substr) {
+		t.Errorf("unexpected %s log containing %q, got:\n%s", logger.LevelText(level), substr, l.dump())
+	}
+}
+
+// dump 把记录的日志按文本格式输出，用于失败时的提示
+func (l *Logger) dump() string {
+	entries := l.Entries()
+	if len(entries) == 0 {
+		return "\t(no logs)"
+	}
+
+	var buf strings.Builder
+	formatter := &logger.TextFormatter{}
+	for i := range entries {
+		buf.WriteByte('\t')
+		buf.Write(formatter.Format(&entries[i]))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,72 @@
+package loggertest_test
+
+import (
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+func TestInstallRestoresLogger(t *testing.T) {
+	prev := loggertest.New()
+	old := logger.SetLogger(prev)
+	defer logger.SetLogger(old)
+
+	var installed *loggertest.Logger
+	t.Run("installed", func(t *testing.T) {
+		installed = loggertest.Install(t)
+		logger.Info("inside")
+		installed.AssertLogged(t, logger.LogLevelInfo, "inside")
+	})
+
+	logger.Info("outside")
+	if cur := logger.SetLogger(prev); cur != prev {
+		t.Errorf("current logger = %T %p, want the previous one", cur, cur)
+	}
+	installed.AssertNotLogged(t, logger.LogLevelInfo, "outside")
+	prev.AssertLogged(t, logger.LogLevelInfo, "outside")
+}
+
+func TestLevelFiltering(t *testing.T) {
+	l := loggertest.Install(t)
+	l.SetLevel(logger.LogLevelWarn)
+
+	logger.Info("info line")
+	logger.Warnw("warn line", "k", 1)
+	l.AssertNotLogged(t, logger.LogLevelInfo, "info line")
+	l.AssertLogged(t, logger.LogLevelWarn, "warn line")
+
+	entries := l.Entries()
+	if len(entries) != 1 || len(entries[0].Fields) != 1 || entries[0].Fields[0].Key != "k" {
+		t.Errorf("entries = %+v", entries)
+	}
+	// 调用位置是打日志的地方，不是loggertest内部
+	if entries[0].Filename != "loggertest_test.go" {
+		t.Errorf("filename = %q", entries[0].Filename)
+	}
+}
+
+func TestResetAndLogged(t *testing.T) {
+	l := loggertest.Install(t)
+
+	logger.Error("failed %d", 1)
+	if !l.Logged(logger.LogLevelError, "failed 1") {
+		t.Error("Logged = false before Reset")
+	}
+	if l.Logged(logger.LogLevelWarn, "failed 1") {
+		t.Error("Logged matched a different level")
+	}
+
+	l.Reset()
+	if len(l.Entries()) != 0 || l.Logged(logger.LogLevelError, "failed") {
+		t.Errorf("entries after Reset = %+v", l.Entries())
+	}
+	l.AssertNotLogged(t, logger.LogLevelError, "failed")
+}
+
+// Install 期间Fatal只记录，不退出进程
+func TestFatalDoesNotExit(t *testing.T) {
+	l := loggertest.Install(t)
+	logger.Fatal("fatal line")
+	l.AssertLogged(t, logger.LogLevelFatal, "fatal line")
+}
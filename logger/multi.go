@@ -0,0 +1,287 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MultiLogger 把同一条日志同时输出到多个LogInterface
+// 每一路输出按照自己的 log_level 过滤
+//
+//	{
+//		"log_sinks": "console,file",
+//		"log_level": "debug",
+//		"console.log_level": "info",
+//		"file.log_path": "./logs",
+//		"file.log_name": "server"
+//	}
+//
+// 不带前缀的配置所有输出共用，带 "<sink>." 前缀的配置只对该输出生效并覆盖共用配置
+// SetLevel 同时修改没有单独配置 "<sink>.log_level" 的输出的级别
+type MultiLogger struct {
+	level logLevel
+
+	lock      sync.RWMutex
+	sinks     []LogInterface
+	ownLevels map[LogInterface]bool // 有自己级别的输出，SetLevel 不修改，只读，修改时整体替换
+}
+
+func NewMultiLogger(config map[string]string) (log LogInterface, err error) {
+	sinkNames, ok := config["log_sinks"]
+	if !ok {
+		err = fmt.Errorf("not found log_sinks ")
+		return
+	}
+
+	level := LogLevelDebug
+	if logLevel, ok := config["log_level"]; ok {
+		level = getLogLevel(logLevel)
+	}
+
+	multi := &MultiLogger{}
+	multi.level.set(level)
+	for _, name := range strings.Split(sinkNames, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == LOGTYPE_MULTI {
+			err = fmt.Errorf("multi logger can not contain multi")
+			return
+		}
+
+		var sink LogInterface
+		sink, err = newLogger(name, sinkConfig(config, name))
+		if err != nil {
+			err = fmt.Errorf("init sink %s failed, err:%v", name, err)
+			return
+		}
+		multi.sinks = append(multi.sinks, sink)
+		if _, ok := config[name+".log_level"]; ok {
+			multi.setOwnLevel(sink)
+		}
+	}
+
+	log = multi
+	return
+}
+
+// sinkConfig 合并共用配置和 "<sink>." 前缀的专属配置
+func sinkConfig(config map[string]string, name string) map[string]string {
+	prefix := name + "."
+	sink := make(map[string]string, len(config))
+	for key, value := range config {
+		if !strings.Contains(key, ".") {
+			sink[key] = value
+		}
+	}
+	for key, value := range config {
+		if strings.HasPrefix(key, prefix) {
+			sink[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	return sink
+}
+
+// setOwnLevel 标记这路输出使用自己的级别，不跟随 SetLevel
+func (m *MultiLogger) setOwnLevel(sink LogInterface) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ownLevels := make(map[LogInterface]bool, len(m.ownLevels)+1)
+	for s := range m.ownLevels {
+		ownLevels[s] = true
+	}
+	ownLevels[sink] = true
+	m.ownLevels = ownLevels
+}
+
+// AddSink 增加一路输出，sink需要已经Init过，之后的 SetLevel 会修改它的级别
+func (m *MultiLogger) AddSink(sink LogInterface) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	sinks := make([]LogInterface, 0, len(m.sinks)+1)
+	sinks = append(sinks, m.sinks...)
+	m.sinks = append(sinks, sink)
+}
+
+func (m *MultiLogger) getSinks() []LogInterface {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sinks
+}
+
+func (m *MultiLogger) Init() {
+	for _, sink := range m.getSinks() {
+		sink.Init()
+	}
+}
+
+// SetLevel 修改整体的级别和没有单独配置级别的输出，单独配置的级别保持不变
+func (m *MultiLogger) SetLevel(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		level = LogLevelDebug
+	}
+
+	m.level.set(level)
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for _, sink := range m.sinks {
+		if !m.ownLevels[sink] {
+			sink.SetLevel(level)
+		}
+	}
+}
+
+func (m *MultiLogger) GetLevel() int {
+	return m.level.get()
+}
+
+func (m *MultiLogger) Debug(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelDebug) {
+		return
+	}
+
+	logData := writeLog(LogLevelDebug, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Trace(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelTrace) {
+		return
+	}
+
+	logData := writeLog(LogLevelTrace, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Info(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelInfo) {
+		return
+	}
+
+	logData := writeLog(LogLevelInfo, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Warn(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelWarn) {
+		return
+	}
+
+	logData := writeLog(LogLevelWarn, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Error(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelError) {
+		return
+	}
+
+	logData := writeLog(LogLevelError, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Fatal(format string, args ...interface{}) {
+	if !m.level.enabled(LogLevelFatal) {
+		return
+	}
+
+	logData := writeLog(LogLevelFatal, format, args...)
+	m.Output(logData)
+}
+
+func (m *MultiLogger) Logw(level int, msg string, fields ...Field) {
+	if !m.level.enabled(level) {
+		return
+	}
+
+	logData := writeLogw(level, msg, fields)
+	m.Output(logData)
+}
+
+// Output 同一个LogData会传给所有输出，各输出只读不改
+func (m *MultiLogger) Output(logData *LogData) {
+	if !m.level.allow(logData) || !sample(logData, m.output) {
+		return
+	}
+
+	m.output(logData)
+}
+
+func (m *MultiLogger) output(logData *LogData) {
+	m.lock.RLock()
+	sinks, ownLevels := m.sinks, m.ownLevels
+	m.lock.RUnlock()
+
+	for _, sink := range sinks {
+		// 按包覆盖的级别不能让单独配置了级别的输出打出更低级别的日志
+		if ownLevels[sink] && logData.Level < sink.GetLevel() {
+			continue
+		}
+		sink.Output(logData)
+	}
+}
+
+func (m *MultiLogger) Flush() {
+	flushSampling()
+	for _, sink := range m.getSinks() {
+		sink.Flush()
+	}
+}
+
+func (m *MultiLogger) Close() {
+	flushSampling()
+	for _, sink := range m.getSinks() {
+		sink.Close()
+	}
+}
+
+// CloseTimeout 依次关闭所有输出，整体不超过timeout
+func (m *MultiLogger) CloseTimeout(timeout time.Duration) error {
+	flushSampling()
+	deadline := time.Now().Add(timeout)
+	for _, sink := range m.getSinks() {
+		closer, ok := sink.(interface {
+			CloseTimeout(timeout time.Duration) error
+		})
+		if !ok {
+			sink.Close()
+			continue
+		}
+
+		remain := time.Until(deadline)
+		if timeout <= 0 {
+			remain = 0
+		} else if remain <= 0 {
+			return fmt.Errorf("close multi logger timeout after %v", timeout)
+		}
+		if err := closer.CloseTimeout(remain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dropped 汇总所有输出丢弃的日志条数
+func (m *MultiLogger) Dropped() map[string]int64 {
+	var dropped map[string]int64
+	for _, sink := range m.getSinks() {
+		counter, ok := sink.(interface {
+			Dropped() map[string]int64
+		})
+		if !ok {
+			continue
+		}
+
+		if dropped == nil {
+			dropped = make(map[string]int64)
+		}
+		for level, count := range counter.Dropped() {
+			dropped[level] += count
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,29 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// 运行时修改级别，没有单独配置级别的输出跟着修改
+func TestMultiLoggerSetLevel(t *testing.T) {
+	rec := loggertest.New()
+	rec.SetLevel(logger.LogLevelInfo)
+	multi := &logger.MultiLogger{}
+	multi.AddSink(rec)
+	multi.SetLevel(logger.LogLevelInfo)
+	old := logger.SetLogger(multi)
+	t.Cleanup(func() { logger.SetLogger(old) })
+
+	logger.Debug("before")
+	logger.SetLevel(logger.LogLevelDebug)
+	logger.Debug("after")
+
+	rec.AssertNotLogged(t, logger.LogLevelDebug, "before")
+	rec.AssertLogged(t, logger.LogLevelDebug, "after")
+	if level := rec.GetLevel(); level != logger.LogLevelDebug {
+		t.Errorf("sink level = %d, want %d", level, logger.LogLevelDebug)
+	}
+}
@@ -0,0 +1,367 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	netDialTimeout  = 5 * time.Second
+	netWriteTimeout = 5 * time.Second
+	netMaxBackoff   = 30 * time.Second
+)
+
+type netMessage struct {
+	level int
+	data  []byte
+}
+
+// netWriter 异步写网络连接，断线后自动重连
+// 未发送的日志缓存在有界队列里，队列满了之后丢弃并计数
+type netWriter struct {
+	network string
+	addr    string
+
+	conn        net.Conn
+	messageChan chan netMessage
+	closeChan   chan struct{}
+	flushChan   chan chan struct{}
+	doneChan    chan struct{}
+	closeOnce   sync.Once
+	closed      atomic.Bool
+	dropped     [LogLevelFatal + 1]atomic.Int64
+}
+
+func newNetWriter(network string, addr string, bufferSize int) *netWriter {
+	if bufferSize <= 0 {
+		bufferSize = 10000
+	}
+
+	return &netWriter{
+		network:     network,
+		addr:        addr,
+		messageChan: make(chan netMessage, bufferSize),
+		closeChan:   make(chan struct{}),
+		flushChan:   make(chan chan struct{}),
+	}
+}
+
+func (w *netWriter) start() {
+	if w.doneChan != nil {
+		return
+	}
+
+	w.doneChan = make(chan struct{})
+	go w.run()
+}
+
+func (w *netWriter) send(level int, data []byte) {
+	if w.closed.Load() {
+		w.drop(level)
+		return
+	}
+
+	select {
+	case w.messageChan <- netMessage{level: level, data: data}:
+	default:
+		w.drop(level)
+	}
+}
+
+func (w *netWriter) drop(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		return
+	}
+	w.dropped[level].Add(1)
+}
+
+func (w *netWriter) run() {
+	defer close(w.doneChan)
+
+	for {
+		select {
+		case message := <-w.messageChan:
+			w.writeRetry(message)
+		case done := <-w.flushChan:
+			w.flush()
+			close(done)
+		case <-w.closeChan:
+			w.flush()
+			if w.conn != nil {
+				w.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// writeRetry 写失败后按指数退避重连，直到写成功或者收到关闭信号
+// 重连期间收到Flush请求时放弃当前这条，避免Flush一直阻塞
+func (w *netWriter) writeRetry(message netMessage) {
+	backoff := 100 * time.Millisecond
+	for {
+		if w.write(message.data) == nil {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case done := <-w.flushChan:
+			w.drop(message.level)
+			w.flush()
+			close(done)
+			return
+		case <-w.closeChan:
+			w.drop(message.level)
+			return
+		}
+
+		backoff *= 2
+		if backoff > netMaxBackoff {
+			backoff = netMaxBackoff
+		}
+	}
+}
+
+// flush 把队列中的日志尽量写完，连接不可用时只尝试一次，剩下的全部丢弃
+func (w *netWriter) flush() {
+	failed := false
+	for {
+		select {
+		case message := <-w.messageChan:
+			if failed {
+				w.drop(message.level)
+				continue
+			}
+			if err := w.write(message.data); err != nil {
+				failed = true
+				w.drop(message.level)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (w *netWriter) write(data []byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.addr, netDialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	w.conn.SetWriteDeadline(time.Now().Add(netWriteTimeout))
+	if _, err := w.conn.Write(data); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (w *netWriter) Flush() {
+	flushSampling()
+	if w.doneChan == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	select {
+	case w.flushChan <- done:
+		<-done
+	case <-w.doneChan:
+	}
+}
+
+func (w *netWriter) CloseTimeout(timeout time.Duration) error {
+	flushSampling()
+	if w.doneChan == nil {
+		return nil
+	}
+
+	w.closeOnce.Do(func() {
+		w.closed.Store(true)
+		close(w.closeChan)
+	})
+
+	if timeout <= 0 {
+		<-w.doneChan
+		return nil
+	}
+
+	select {
+	case <-w.doneChan:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("close %s logger %s timeout after %v", w.network, w.addr, timeout)
+	}
+}
+
+func (w *netWriter) Dropped() map[string]int64 {
+	dropped := make(map[string]int64, len(w.dropped))
+	for level := range w.dropped {
+		dropped[getLevelText(level)] = w.dropped[level].Load()
+	}
+	return dropped
+}
+
+// NetLogger 把日志逐行写到远端的TCP服务
+//
+//	{
+//		"log_level": "info",
+//		"log_addr": "127.0.0.1:5170",
+//		"log_network": "tcp",
+//		"log_buffer_size": "10000",
+//		"log_format": "json"
+//	}
+type NetLogger struct {
+	level        logLevel
+	formatter    Formatter
+	writer       *netWriter
+	closeTimeout time.Duration
+}
+
+func NewNetLogger(config map[string]string) (log LogInterface, err error) {
+	logLevel, ok := config["log_level"]
+	if !ok {
+		err = fmt.Errorf("not found log_level ")
+		return
+	}
+
+	logAddr, ok := config["log_addr"]
+	if !ok {
+		err = fmt.Errorf("not found log_addr ")
+		return
+	}
+
+	network, ok := config["log_network"]
+	if !ok {
+		network = "tcp"
+	}
+
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return
+	}
+
+	netLogger := &NetLogger{
+		formatter:    formatter,
+		writer:       newNetWriter(network, logAddr, configInt(config, "log_buffer_size", 10000)),
+		closeTimeout: time.Duration(configInt(config, "log_close_timeout", 5000)) * time.Millisecond,
+	}
+	netLogger.level.set(getLogLevel(logLevel))
+	log = netLogger
+	return
+}
+
+func (n *NetLogger) Init() {
+	n.writer.start()
+}
+
+func (n *NetLogger) SetLevel(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		level = LogLevelDebug
+	}
+
+	n.level.set(level)
+}
+
+func (n *NetLogger) GetLevel() int {
+	return n.level.get()
+}
+
+func (n *NetLogger) Debug(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelDebug) {
+		return
+	}
+
+	logData := writeLog(LogLevelDebug, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Trace(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelTrace) {
+		return
+	}
+
+	logData := writeLog(LogLevelTrace, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Info(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelInfo) {
+		return
+	}
+
+	logData := writeLog(LogLevelInfo, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Warn(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelWarn) {
+		return
+	}
+
+	logData := writeLog(LogLevelWarn, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Error(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelError) {
+		return
+	}
+
+	logData := writeLog(LogLevelError, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Fatal(format string, args ...interface{}) {
+	if !n.level.enabled(LogLevelFatal) {
+		return
+	}
+
+	logData := writeLog(LogLevelFatal, format, args...)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Logw(level int, msg string, fields ...Field) {
+	if !n.level.enabled(level) {
+		return
+	}
+
+	logData := writeLogw(level, msg, fields)
+	n.Output(logData)
+}
+
+func (n *NetLogger) Output(logData *LogData) {
+	if !n.level.allow(logData) || !sample(logData, n.output) {
+		return
+	}
+
+	n.output(logData)
+}
+
+func (n *NetLogger) output(logData *LogData) {
+	n.writer.send(logData.Level, n.formatter.Format(logData))
+}
+
+func (n *NetLogger) Flush() {
+	n.writer.Flush()
+}
+
+func (n *NetLogger) Close() {
+	n.writer.CloseTimeout(n.closeTimeout)
+}
+
+func (n *NetLogger) CloseTimeout(timeout time.Duration) error {
+	return n.writer.CloseTimeout(timeout)
+}
+
+func (n *NetLogger) Dropped() map[string]int64 {
+	return n.writer.Dropped()
+}
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampler 按调用位置对重复日志采样，关闭调用位置(EnableCaller(false))时按格式化之前的消息采样
+// 每个周期内同一位置的前 initial 条全部输出，之后每 thereafter 条输出1条
+// 周期结束后输出一条WARN日志，说明上个周期被丢弃的条数
+// 同一位置后面没有新的日志时，由定时器或者Flush/Close输出汇总
+type sampler struct {
+	initial    int64
+	thereafter int64
+	interval   time.Duration
+
+	lock  sync.Mutex
+	sites map[sampleSite]*sampleCounter
+	timer *time.Timer
+}
+
+type sampleSite struct {
+	fileName string
+	funcName string
+	lineNo   int
+	format   string // 只在没有调用位置时使用
+}
+
+type sampleCounter struct {
+	start      time.Time
+	count      int64
+	suppressed int64
+	// 最后一条被丢弃日志的输出，用于定时输出汇总
+	output func(*LogData)
+}
+
+// 为nil表示不采样
+var logSampler atomic.Pointer[sampler]
+
+// SetSampling 设置采样规则，initial <= 0 时关闭采样
+// thereafter <= 0 表示超过 initial 之后全部丢弃
+func SetSampling(initial int, thereafter int, interval time.Duration) {
+	var s *sampler
+	if initial > 0 {
+		if interval <= 0 {
+			interval = time.Second
+		}
+		s = &sampler{
+			initial:    int64(initial),
+			thereafter: int64(thereafter),
+			interval:   interval,
+			sites:      make(map[sampleSite]*sampleCounter),
+		}
+	}
+
+	// 旧规则下还没输出的汇总直接输出
+	if old := logSampler.Swap(s); old != nil {
+		old.flush(time.Time{})
+	}
+}
+
+// flushSampling 输出所有还没输出的采样汇总，在Flush/Close时调用
+func flushSampling() {
+	if s := logSampler.Load(); s != nil {
+		s.flush(time.Time{})
+	}
+}
+
+// configureSampling 读取 log_sample_initial, log_sample_thereafter, log_sample_interval(秒)
+func configureSampling(config map[string]string) {
+	SetSampling(configInt(config, "log_sample_initial", 0),
+		configInt(config, "log_sample_thereafter", 0),
+		time.Duration(configInt(config, "log_sample_interval", 1))*time.Second)
+}
+
+// sample 判断这条日志是否输出，需要输出采样汇总时通过output写出
+// 一条日志只采样一次，MultiLogger分发给各个输出时不会重复计数
+func sample(logData *LogData, output func(*LogData)) bool {
+	if logData.sampled {
+		return true
+	}
+
+	s := logSampler.Load()
+	if s == nil {
+		return true
+	}
+
+	allow, summary := s.check(logData, output)
+	if summary != nil {
+		output(summary)
+	}
+	if allow {
+		logData.sampled = true
+	}
+	return allow
+}
+
+func (s *sampler) check(logData *LogData, output func(*LogData)) (bool, *LogData) {
+	site := sampleSite{
+		fileName: logData.Filename,
+		funcName: logData.FuncName,
+		lineNo:   logData.LineNo,
+	}
+	if site.fileName == "" {
+		site.format = logData.format
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	counter, ok := s.sites[site]
+	if !ok {
+		counter = &sampleCounter{start: logData.Time}
+		s.sites[site] = counter
+	}
+
+	var summary *LogData
+	if logData.Time.Sub(counter.start) >= s.interval {
+		summary = counter.summary(site, logData.Time)
+		counter.start = logData.Time
+		counter.count = 0
+		counter.suppressed = 0
+	}
+
+	counter.count++
+	if counter.count <= s.initial {
+		return true, summary
+	}
+	if s.thereafter > 0 && (counter.count-s.initial)%s.thereafter == 0 {
+		return true, summary
+	}
+	counter.suppressed++
+	counter.output = output
+	s.startTimer(counter.start.Add(s.interval))
+	return false, summary
+}
+
+// summary 生成上个周期的汇总日志，没有丢弃日志时返回nil
+func (c *sampleCounter) summary(site sampleSite, now time.Time) *LogData {
+	if c.suppressed == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("repeated logs suppressed in last %v", now.Sub(c.start).Truncate(time.Millisecond))
+	fields := []Field{{Key: "suppressed", Value: c.suppressed}}
+	if site.fileName == "" {
+		// 没有调用位置，用消息说明是哪条日志
+		fields = append(fields, Field{Key: "format", Value: site.format})
+	}
+	summary := newLogData(LogLevelWarn, msg, fields, site.fileName, site.funcName, site.lineNo)
+	summary.sampled = true
+	return summary
+}
+
+// startTimer 在at时刻检查并输出到期的汇总，已经有定时器时不重复启动，需要持有锁
+func (s *sampler) startTimer(at time.Time) {
+	if s.timer != nil {
+		return
+	}
+	s.timer = time.AfterFunc(time.Until(at), func() {
+		s.flush(time.Now())
+	})
+}
+
+// flush 输出周期已经结束的汇总，now为零值时输出所有的汇总
+// 输出过汇总的位置会被删除，下一条日志重新开始计数
+func (s *sampler) flush(now time.Time) {
+	type pending struct {
+		summary *LogData
+		output  func(*LogData)
+	}
+
+	s.lock.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	var summaries []pending
+	var next time.Time
+	for site, counter := range s.sites {
+		end := counter.start.Add(s.interval)
+		if now.IsZero() && time.Now().Before(end) {
+			// 周期还没结束，输出已经丢弃的条数，继续计数
+			if summary := counter.summary(site, time.Now()); summary != nil {
+				summaries = append(summaries, pending{summary, counter.output})
+			}
+			counter.suppressed = 0
+			continue
+		}
+		if !now.IsZero() && now.Before(end) {
+			if counter.suppressed > 0 && (next.IsZero() || end.Before(next)) {
+				next = end
+			}
+			continue
+		}
+
+		if summary := counter.summary(site, end); summary != nil {
+			summaries = append(summaries, pending{summary, counter.output})
+		}
+		delete(s.sites, site)
+	}
+	if !next.IsZero() {
+		s.startTimer(next)
+	}
+	s.lock.Unlock()
+
+	// 在锁外输出，输出可能再次进入采样
+	for _, p := range summaries {
+		p.output(p.summary)
+	}
+}
@@ -0,0 +1,109 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+)
+
+// installSampling loggertest 自己不采样，放在MultiLogger后面才能看到采样的结果
+func installSampling(t *testing.T, initial int, thereafter int, interval time.Duration) *loggertest.Logger {
+	t.Helper()
+	rec := loggertest.New()
+	multi := &logger.MultiLogger{}
+	multi.AddSink(rec)
+	old := logger.SetLogger(multi)
+	logger.SetSampling(initial, thereafter, interval)
+	t.Cleanup(func() {
+		logger.SetSampling(0, 0, 0)
+		logger.SetLogger(old)
+	})
+	return rec
+}
+
+// suppressed 返回采样汇总日志里丢弃的条数，没有汇总时返回-1
+func suppressed(rec *loggertest.Logger) int64 {
+	for _, entry := range rec.Entries() {
+		if entry.Level != logger.LogLevelWarn {
+			continue
+		}
+		for _, field := range entry.Fields {
+			if field.Key == "suppressed" {
+				return field.Value.(int64)
+			}
+		}
+	}
+	return -1
+}
+
+func TestSamplingSameSite(t *testing.T) {
+	rec := installSampling(t, 2, 3, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("sample %d", i)
+	}
+
+	// 前2条全部输出，之后每3条输出1条: 0, 1, 4, 7
+	for _, msg := range []string{"sample 0", "sample 1", "sample 4", "sample 7"} {
+		rec.AssertLogged(t, logger.LogLevelInfo, msg)
+	}
+	rec.AssertNotLogged(t, logger.LogLevelInfo, "sample 2")
+	if n := len(rec.Entries()); n != 4 {
+		t.Errorf("logged %d entries, want 4", n)
+	}
+}
+
+func TestSamplingSummaryOnClose(t *testing.T) {
+	rec := installSampling(t, 1, 0, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("flush %d", i)
+	}
+	if n := suppressed(rec); n != -1 {
+		t.Fatalf("summary written before close, suppressed=%d", n)
+	}
+
+	// 关闭时输出还没有输出的汇总
+	logger.CloseLogger()
+	rec.AssertLogged(t, logger.LogLevelWarn, "repeated logs suppressed")
+	if n := suppressed(rec); n != 4 {
+		t.Errorf("suppressed = %d, want 4", n)
+	}
+}
+
+func TestSamplingSummaryOnTimer(t *testing.T) {
+	rec := installSampling(t, 1, 0, 50*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("timer %d", i)
+	}
+
+	// 同一位置没有新的日志，由定时器输出汇总
+	deadline := time.Now().Add(2 * time.Second)
+	for suppressed(rec) == -1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := suppressed(rec); n != 4 {
+		t.Errorf("suppressed = %d, want 4", n)
+	}
+}
+
+func TestSamplingWithoutCaller(t *testing.T) {
+	rec := installSampling(t, 1, 0, time.Hour)
+	logger.EnableCaller(false)
+	t.Cleanup(func() { logger.EnableCaller(true) })
+
+	// 没有调用位置时按格式化之前的消息区分，不同的消息互不影响
+	for i := 0; i < 3; i++ {
+		logger.Info("first %d", i)
+		logger.Info("second %d", i)
+	}
+	rec.AssertLogged(t, logger.LogLevelInfo, "first 0")
+	rec.AssertLogged(t, logger.LogLevelInfo, "second 0")
+	rec.AssertNotLogged(t, logger.LogLevelInfo, "first 1")
+	if n := len(rec.Entries()); n != 2 {
+		t.Errorf("logged %d entries, want 2", n)
+	}
+}
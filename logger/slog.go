@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// slog和本包日志级别的对应关系
+// Debug <= -4 < Trace < 0 <= Info < 4 <= Warn < 8 <= Error < 12 <= Fatal
+const slogLevelFatal = slog.LevelError + 4
+
+func fromSlogLevel(level slog.Level) int {
+	switch {
+	case level <= slog.LevelDebug:
+		return LogLevelDebug
+	case level < slog.LevelInfo:
+		return LogLevelTrace
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarn
+	case level < slogLevelFatal:
+		return LogLevelError
+	}
+	return LogLevelFatal
+}
+
+func toSlogLevel(level int) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelTrace:
+		return slog.LevelDebug + 2
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	}
+	return slogLevelFatal
+}
+
+// SlogHandler 把slog的日志写到当前的日志实例
+// slog.New(logger.NewSlogHandler()).Info("msg", "k", v)
+// 属性作为结构化字段输出，分组展开成 "group.key"
+type SlogHandler struct {
+	fields []Field
+	prefix string
+}
+
+func NewSlogHandler() *SlogHandler {
+	return &SlogHandler{}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return logEnabled(fromSlogLevel(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var fileName, funcName string
+	var lineNo int
+	if record.PC != 0 && !callerDisabled.Load() {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		fileName, funcName, lineNo = frame.File, frame.Function, frame.Line
+	}
+
+	fields := make([]Field, 0, len(h.fields)+record.NumAttrs())
+	fields = append(fields, h.fields...)
+	fields = append(fields, contextFields(ctx)...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	logData := newLogData(fromSlogLevel(record.Level), record.Message, fields, fileName, funcName, lineNo)
+	if !record.Time.IsZero() {
+		logData.Time = record.Time
+		logData.TimeStr = formatLogTime(record.Time)
+	}
+	getLog().Output(logData)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(h.fields)+len(attrs))
+	fields = append(fields, h.fields...)
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, attr)
+	}
+	return &SlogHandler{fields: fields, prefix: h.prefix}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// appendSlogAttr 展开分组属性，key加上分组前缀
+func appendSlogAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			fields = appendSlogAttr(fields, groupPrefix, groupAttr)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
+
+// SlogLogger 把日志写到任意的slog.Handler
+type SlogLogger struct {
+	level   logLevel
+	handler slog.Handler
+}
+
+// NewSlogLogger log_level 可选，默认debug，slog.Handler自身的级别同样生效
+func NewSlogLogger(handler slog.Handler, config map[string]string) (log LogInterface, err error) {
+	if handler == nil {
+		err = fmt.Errorf("slog handler is nil")
+		return
+	}
+
+	level := LogLevelDebug
+	if logLevel, ok := config["log_level"]; ok {
+		level = getLogLevel(logLevel)
+	}
+
+	slogLogger := &SlogLogger{
+		handler: handler,
+	}
+	slogLogger.level.set(level)
+	log = slogLogger
+	return
+}
+
+func (s *SlogLogger) Init() {
+
+}
+
+func (s *SlogLogger) SetLevel(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		level = LogLevelDebug
+	}
+
+	s.level.set(level)
+}
+
+func (s *SlogLogger) GetLevel() int {
+	return s.level.get()
+}
+
+func (s *SlogLogger) Debug(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelDebug) {
+		return
+	}
+
+	logData := writeLog(LogLevelDebug, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Trace(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelTrace) {
+		return
+	}
+
+	logData := writeLog(LogLevelTrace, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Info(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelInfo) {
+		return
+	}
+
+	logData := writeLog(LogLevelInfo, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Warn(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelWarn) {
+		return
+	}
+
+	logData := writeLog(LogLevelWarn, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Error(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelError) {
+		return
+	}
+
+	logData := writeLog(LogLevelError, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Fatal(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelFatal) {
+		return
+	}
+
+	logData := writeLog(LogLevelFatal, format, args...)
+	s.Output(logData)
+}
+
+func (s *SlogLogger) Logw(level int, msg string, fields ...Field) {
+	if !s.level.enabled(level) {
+		return
+	}
+
+	logData := writeLogw(level, msg, fields)
+	s.Output(logData)
+}
+
+// Output 调用位置作为 caller 属性输出，结构化字段转换成slog属性
+func (s *SlogLogger) Output(logData *LogData) {
+	if !s.level.allow(logData) || !sample(logData, s.output) {
+		return
+	}
+
+	s.output(logData)
+}
+
+func (s *SlogLogger) output(logData *LogData) {
+	ctx := context.Background()
+	level := toSlogLevel(logData.Level)
+	if !s.handler.Enabled(ctx, level) {
+		return
+	}
+
+	record := slog.NewRecord(logData.Time, level, logData.Message, 0)
+	if logData.Filename != "" {
+		record.AddAttrs(slog.String("caller",
+			fmt.Sprintf("%s:%s:%d", logData.Filename, logData.FuncName, logData.LineNo)))
+	}
+	for _, field := range logData.Fields {
+		record.AddAttrs(slog.Any(field.Key, field.Value))
+	}
+	if logData.Stack != "" {
+		record.AddAttrs(slog.String("stack", logData.Stack))
+	}
+	s.handler.Handle(ctx, record)
+}
+
+func (s *SlogLogger) Flush() {
+	flushSampling()
+}
+
+func (s *SlogLogger) Close() {
+	flushSampling()
+}
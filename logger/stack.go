@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// 调用栈最多记录的层数
+const maxStackDepth = 32
+
+// 达到这个级别的日志附带调用栈，默认不附带
+var stackLevel atomic.Int32
+
+func init() {
+	stackLevel.Store(LogLevelFatal + 1)
+}
+
+// SetStackLevel 设置附带调用栈的最低级别，超过LogLevelFatal表示关闭
+func SetStackLevel(level int) {
+	stackLevel.Store(int32(level))
+}
+
+// parseStackLevel 读取 log_stack_level，为空时不附带调用栈
+func parseStackLevel(config map[string]string) (int, error) {
+	name, ok := config["log_stack_level"]
+	if !ok || name == "" {
+		return LogLevelFatal + 1, nil
+	}
+
+	level, err := ParseLevel(name)
+	if err != nil {
+		return 0, fmt.Errorf("unsupport log_stack_level:%s", name)
+	}
+	return level, nil
+}
+
+func stackEnabled(level int) bool {
+	return level >= int(stackLevel.Load())
+}
+
+// callerStack 返回当前goroutine的调用栈
+// 去掉本包、runtime和slog内部的函数，只保留业务代码相关的部分
+func callerStack() string {
+	var pcs [maxStackDepth + 16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var buf strings.Builder
+	depth := 0
+	for depth < maxStackDepth {
+		frame, more := frames.Next()
+		if !inLoggerPackage(frame.Function) &&
+			!strings.HasPrefix(frame.Function, "runtime.") &&
+			!strings.HasPrefix(frame.Function, "log/slog.") {
+			fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			depth++
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ErrorStack 打印Error日志，不管 log_stack_level 的配置都附带调用栈
+// 用于recover之后记录panic的位置
+func ErrorStack(format string, args ...interface{}) {
+	if !logEnabled(LogLevelError) {
+		return
+	}
+
+	fileName, funcName, lineNo := GetLineInfo()
+	logData := newLogData(LogLevelError, fmt.Sprintf(format, args...), nil, fileName, funcName, lineNo)
+	logData.format = format
+	if logData.Stack == "" {
+		logData.Stack = callerStack()
+	}
+	getLog().Output(logData)
+}
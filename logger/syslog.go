@@ -0,0 +1,268 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var syslogFacilities = map[string]int{
+	"kern":   0,
+	"user":   1,
+	"mail":   2,
+	"daemon": 3,
+	"auth":   4,
+	"syslog": 5,
+	"lpr":    6,
+	"news":   7,
+	"uucp":   8,
+	"cron":   9,
+	"local0": 16,
+	"local1": 17,
+	"local2": 18,
+	"local3": 19,
+	"local4": 20,
+	"local5": 21,
+	"local6": 22,
+	"local7": 23,
+}
+
+func getSyslogSeverity(level int) int {
+	switch level {
+	case LogLevelDebug, LogLevelTrace:
+		return 7
+	case LogLevelInfo:
+		return 6
+	case LogLevelWarn:
+		return 4
+	case LogLevelError:
+		return 3
+	}
+	return 2
+}
+
+// SyslogLogger 按RFC 5424格式发送日志到syslog
+// log_network 支持 udp, tcp, unix, unixgram，tcp和unix使用octet-counting分帧
+//
+//	{
+//		"log_level": "info",
+//		"log_network": "udp",
+//		"log_addr": "127.0.0.1:514",
+//		"log_tag": "server",
+//		"log_facility": "local0"
+//	}
+//
+// log_format 默认为msg，时间和级别已经在syslog头里，MSG只包含消息和字段
+type SyslogLogger struct {
+	level        logLevel
+	formatter    Formatter
+	writer       *netWriter
+	closeTimeout time.Duration
+
+	facility   int
+	hostname   string
+	tag        string
+	pid        int
+	octetCount bool
+}
+
+func NewSyslogLogger(config map[string]string) (log LogInterface, err error) {
+	logLevel, ok := config["log_level"]
+	if !ok {
+		err = fmt.Errorf("not found log_level ")
+		return
+	}
+
+	logAddr, ok := config["log_addr"]
+	if !ok {
+		err = fmt.Errorf("not found log_addr ")
+		return
+	}
+
+	network, ok := config["log_network"]
+	if !ok {
+		network = "udp"
+	}
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		err = fmt.Errorf("unsupport log_network:%s", network)
+		return
+	}
+
+	facility := syslogFacilities["local0"]
+	if facilityStr, ok := config["log_facility"]; ok {
+		facility, ok = syslogFacilities[facilityStr]
+		if !ok {
+			err = fmt.Errorf("unsupport log_facility:%s", facilityStr)
+			return
+		}
+	}
+
+	tag, ok := config["log_tag"]
+	if !ok {
+		tag = filepath.Base(os.Args[0])
+	}
+	tag = syslogAppName(tag)
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	formatConfig := config
+	if _, ok := config["log_format"]; !ok {
+		formatConfig = map[string]string{"log_format": LOGFORMAT_MSG}
+	}
+	formatter, err := newFormatter(formatConfig)
+	if err != nil {
+		return
+	}
+
+	syslogLogger := &SyslogLogger{
+		formatter:    formatter,
+		writer:       newNetWriter(network, logAddr, configInt(config, "log_buffer_size", 10000)),
+		closeTimeout: time.Duration(configInt(config, "log_close_timeout", 5000)) * time.Millisecond,
+		facility:     facility,
+		hostname:     hostname,
+		tag:          tag,
+		pid:          os.Getpid(),
+		octetCount:   !strings.HasPrefix(network, "udp") && network != "unixgram",
+	}
+	syslogLogger.level.set(getLogLevel(logLevel))
+	log = syslogLogger
+	return
+}
+
+// syslogAppName 按RFC 5424的APP-NAME规则处理tag：只保留可见的ASCII字符，最长48个
+func syslogAppName(tag string) string {
+	name := make([]byte, 0, len(tag))
+	for i := 0; i < len(tag) && len(name) < 48; i++ {
+		if tag[i] >= 33 && tag[i] <= 126 {
+			name = append(name, tag[i])
+		}
+	}
+	if len(name) == 0 {
+		return "-"
+	}
+	return string(name)
+}
+
+func (s *SyslogLogger) Init() {
+	s.writer.start()
+}
+
+func (s *SyslogLogger) SetLevel(level int) {
+	if level < LogLevelDebug || level > LogLevelFatal {
+		level = LogLevelDebug
+	}
+
+	s.level.set(level)
+}
+
+func (s *SyslogLogger) GetLevel() int {
+	return s.level.get()
+}
+
+func (s *SyslogLogger) Debug(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelDebug) {
+		return
+	}
+
+	logData := writeLog(LogLevelDebug, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Trace(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelTrace) {
+		return
+	}
+
+	logData := writeLog(LogLevelTrace, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Info(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelInfo) {
+		return
+	}
+
+	logData := writeLog(LogLevelInfo, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Warn(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelWarn) {
+		return
+	}
+
+	logData := writeLog(LogLevelWarn, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Error(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelError) {
+		return
+	}
+
+	logData := writeLog(LogLevelError, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Fatal(format string, args ...interface{}) {
+	if !s.level.enabled(LogLevelFatal) {
+		return
+	}
+
+	logData := writeLog(LogLevelFatal, format, args...)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Logw(level int, msg string, fields ...Field) {
+	if !s.level.enabled(level) {
+		return
+	}
+
+	logData := writeLogw(level, msg, fields)
+	s.Output(logData)
+}
+
+func (s *SyslogLogger) Output(logData *LogData) {
+	if !s.level.allow(logData) || !sample(logData, s.output) {
+		return
+	}
+
+	s.output(logData)
+}
+
+// output <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogLogger) output(logData *LogData) {
+	msg := strings.TrimRight(string(s.formatter.Format(logData)), "\n")
+	line := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		s.facility*8+getSyslogSeverity(logData.Level),
+		logData.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, s.tag, s.pid, msg)
+
+	if s.octetCount {
+		line = fmt.Sprintf("%d %s", len(line), line)
+	}
+	s.writer.send(logData.Level, []byte(line))
+}
+
+func (s *SyslogLogger) Flush() {
+	s.writer.Flush()
+}
+
+func (s *SyslogLogger) Close() {
+	s.writer.CloseTimeout(s.closeTimeout)
+}
+
+func (s *SyslogLogger) CloseTimeout(timeout time.Duration) error {
+	return s.writer.CloseTimeout(timeout)
+}
+
+func (s *SyslogLogger) Dropped() map[string]int64 {
+	return s.writer.Dropped()
+}
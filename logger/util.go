@@ -3,30 +3,77 @@ package logger
 import (
 	"fmt"
 	"path"
-	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Field 结构化日志的一个键值对
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
 type LogData struct {
 	Message      string
+	Time         time.Time
 	TimeStr      string
 	LevelStr     string
+	Level        int
 	Filename     string
 	FuncName     string
 	LineNo       int
 	WarnAndFatal bool
+	Fields       []Field
+	Stack        string // 调用栈，只有达到 log_stack_level 的日志才有
+
+	sampled bool   // 已经通过采样，多路输出时不再重复采样
+	pooled  bool   // 从对象池获取，写完后放回
+	format  string // 格式化之前的消息，关闭调用位置时用于采样
+}
+
+var logDataPool = sync.Pool{
+	New: func() interface{} { return new(LogData) },
+}
+
+// releaseLogData 把对象池里取出的日志放回，其它日志不处理
+func releaseLogData(logData *LogData) {
+	if !logData.pooled {
+		return
+	}
+	*logData = LogData{}
+	logDataPool.Put(logData)
 }
 
-//util.go 10
+// 同一秒内的日志复用格式化好的时间前缀
+type cachedTime struct {
+	unix   int64
+	prefix string
+}
+
+var logTimeCache atomic.Pointer[cachedTime]
+
+// formatLogTime 与 t.Format("2006-01-02 15:04:05.999") 的结果相同
+func formatLogTime(t time.Time) string {
+	cache := logTimeCache.Load()
+	if cache == nil || cache.unix != t.Unix() {
+		cache = &cachedTime{unix: t.Unix(), prefix: t.Format("2006-01-02 15:04:05")}
+		logTimeCache.Store(cache)
+	}
 
-func GetLineInfo() (fileName string, funcName string, lineNo int) {
-	pc, file, line, ok := runtime.Caller(4)
-	if ok {
-		fileName = file
-		funcName = runtime.FuncForPC(pc).Name()
-		lineNo = line
+	ms := t.Nanosecond() / int(time.Millisecond)
+	if ms == 0 {
+		return cache.prefix
 	}
-	return
+
+	// 和 .999 一样去掉末尾的0
+	frac := []byte{'.', byte('0' + ms/100), byte('0' + ms/10%10), byte('0' + ms%10)}
+	for frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+	return cache.prefix + string(frac)
 }
 
 /*
@@ -34,29 +81,154 @@ func GetLineInfo() (fileName string, funcName string, lineNo int) {
 2. 然后我们有一个后台的线程不断的从chan里面获取这些日志，最终写入到文件。
 */
 func writeLog(level int, format string, args ...interface{}) *LogData {
+	fileName, funcName, lineNo := GetLineInfo()
+	logData := newLogData(level, fmt.Sprintf(format, args...), nil, fileName, funcName, lineNo)
+	logData.format = format
+	return logData
+	//fmt.Fprintf(file, "%s %s (%s:%s:%d) %s\n", nowStr, levelStr, fileName, funcName, lineNo, msg)
+}
+
+// writeLogw 与writeLog相同，但消息不做格式化，并携带结构化字段
+func writeLogw(level int, msg string, fields []Field) *LogData {
+	fileName, funcName, lineNo := GetLineInfo()
+	return newLogData(level, msg, fields, fileName, funcName, lineNo)
+}
+
+// NewLogData 按当前的调用位置构造一条日志，用于在本包之外实现 LogInterface
+// 调用位置会跳过本包和loggertest内的函数，级别达到 log_stack_level 时附带调用栈
+func NewLogData(level int, msg string, fields []Field) *LogData {
+	fileName, funcName, lineNo := GetLineInfo()
+	return newLogData(level, msg, fields, fileName, funcName, lineNo)
+}
+
+// writePooledLog 与writeLog相同，但LogData从对象池获取
+// 只能用于日志实例自己创建、写完之后不再被引用的日志，写完后调用releaseLogData
+func writePooledLog(level int, format string, args ...interface{}) *LogData {
+	fileName, funcName, lineNo := GetLineInfo()
+	logData := logDataPool.Get().(*LogData)
+	initLogData(logData, level, fmt.Sprintf(format, args...), nil, fileName, funcName, lineNo)
+	logData.pooled = true
+	logData.format = format
+	return logData
+}
+
+func writePooledLogw(level int, msg string, fields []Field) *LogData {
+	fileName, funcName, lineNo := GetLineInfo()
+	logData := logDataPool.Get().(*LogData)
+	initLogData(logData, level, msg, fields, fileName, funcName, lineNo)
+	logData.pooled = true
+	return logData
+}
+
+func newLogData(level int, msg string, fields []Field, fileName string, funcName string, lineNo int) *LogData {
+	logData := &LogData{}
+	initLogData(logData, level, msg, fields, fileName, funcName, lineNo)
+	return logData
+}
+
+func initLogData(logData *LogData, level int, msg string, fields []Field, fileName string, funcName string, lineNo int) {
 	now := time.Now()
-	nowStr := now.Format("2006-01-02 15:04:05.999")
+	nowStr := formatLogTime(now)
 	levelStr := getLevelText(level)
 
-	fileName, funcName, lineNo := GetLineInfo()
-	fileName = path.Base(fileName)
-	funcName = path.Base(funcName)
-	msg := fmt.Sprintf(format, args...)
+	if fileName != "" && !callerFullPath.Load() {
+		fileName = path.Base(fileName)
+	}
+	if funcName != "" {
+		funcName = path.Base(funcName)
+	}
 
-	logData := &LogData{
+	*logData = LogData{
 		Message:      msg,
+		Time:         now,
 		TimeStr:      nowStr,
 		LevelStr:     levelStr,
+		Level:        level,
 		Filename:     fileName,
 		FuncName:     funcName,
 		LineNo:       lineNo,
 		WarnAndFatal: false,
+		Fields:       fields,
+		format:       msg,
 	}
 
 	if level == LogLevelError || level == LogLevelWarn || level == LogLevelFatal {
 		logData.WarnAndFatal = true
 	}
+	if stackEnabled(level) {
+		logData.Stack = callerStack()
+	}
+}
 
-	return logData
-	//fmt.Fprintf(file, "%s %s (%s:%s:%d) %s\n", nowStr, levelStr, fileName, funcName, lineNo, msg)
+// configInt 读取整数配置，不存在或格式错误时返回默认值
+func configInt(config map[string]string, key string, defaultValue int) int {
+	str, ok := config[key]
+	if !ok {
+		return defaultValue
+	}
+
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// toFields 把 "k1", v1, "k2", v2 形式的参数转换成Field
+func toFields(kv []interface{}) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+
+		if i+1 >= len(kv) {
+			fields = append(fields, Field{Key: key, Value: "(MISSING)"})
+			break
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+	return fields
+}
+
+// fieldsText 把字段渲染成 " k1=v1 k2=v2"，没有字段时返回空串
+func fieldsText(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteByte(' ')
+		b.WriteString(field.Key)
+		b.WriteByte('=')
+		b.WriteString(fieldValueText(field.Value))
+	}
+	return b.String()
+}
+
+func fieldValueText(value interface{}) string {
+	var str string
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		str = v
+	case error:
+		str = v.Error()
+	case fmt.Stringer:
+		str = v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+
+	if str == "" || strings.ContainsAny(str, " =\"\t\r\n") {
+		return strconv.Quote(str)
+	}
+	return str
 }
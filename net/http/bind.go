@@ -0,0 +1,161 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// BindJSON 解析JSON请求体并按 validate 标签校验
+// 请求体格式错误返回400的 HttpError，校验失败返回 ValidationError
+func (req *HttpRequest) BindJSON(v interface{}) error {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		return &HttpError{Status: http.StatusBadRequest, Message: "invalid json body", Err: err}
+	}
+	return validate(v, "json")
+}
+
+// BindQuery 按 query 标签从URL参数中填充结构体并校验
+//
+//	type ListUsers struct {
+//		Page int    `query:"page" validate:"min=1"`
+//		Name string `query:"name"`
+//	}
+func (req *HttpRequest) BindQuery(v interface{}) error {
+	if err := bindValues(v, req.URL.Query(), "query"); err != nil {
+		return err
+	}
+	return validate(v, "query")
+}
+
+// BindForm 按 form 标签从表单中填充结构体并校验，同时包含URL参数
+func (req *HttpRequest) BindForm(v interface{}) error {
+	if err := req.ParseForm(); err != nil {
+		return &HttpError{Status: http.StatusBadRequest, Message: "invalid form body", Err: err}
+	}
+	if err := bindValues(v, req.Form, "form"); err != nil {
+		return err
+	}
+	return validate(v, "form")
+}
+
+// bindValues 支持字符串、布尔、数字，以及它们的切片和指针
+func bindValues(v interface{}, values url.Values, nameTag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a struct pointer, got %T", v)
+	}
+
+	var errs []FieldError
+	bindStruct(rv.Elem(), values, nameTag, nil, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// bindStruct 返回是否有字段从参数中赋值，parents 是正在赋值的外层类型，避免嵌入自己时无限分配
+func bindStruct(rv reflect.Value, values url.Values, nameTag string, parents []reflect.Type, errs *[]FieldError) bool {
+	bound := false
+	rt := rv.Type()
+	parents = append(parents, rt)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		// 匿名嵌入的结构体，字段和外层平铺，nil指针在有字段赋值时才分配
+		if et, ok := embeddedStruct(field, nameTag); ok {
+			switch {
+			case fv.Kind() != reflect.Pointer:
+				bound = bindStruct(fv, values, nameTag, parents, errs) || bound
+			case !fv.IsNil():
+				bound = bindStruct(fv.Elem(), values, nameTag, parents, errs) || bound
+			case fv.CanSet() && !slices.Contains(parents, et): // 没有导出的nil指针无法分配，嵌入自己的类型不再分配
+				elem := reflect.New(et)
+				if bindStruct(elem.Elem(), values, nameTag, parents, errs) {
+					fv.Set(elem)
+					bound = true
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field, nameTag)
+		vals, ok := values[name]
+		if name == "-" || !ok || len(vals) == 0 {
+			continue
+		}
+
+		bound = true
+		if err := setField(fv, vals); err != nil {
+			*errs = append(*errs, FieldError{
+				Field:   name,
+				Rule:    "type",
+				Message: fmt.Sprintf("must be %s", field.Type),
+			})
+		}
+	}
+	return bound
+}
+
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() != reflect.Slice {
+		return setValue(fv, vals[0])
+	}
+
+	slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+	for i, val := range vals {
+		if err := setValue(slice.Index(i), val); err != nil {
+			return err
+		}
+	}
+	fv.Set(slice)
+	return nil
+}
+
+func setValue(fv reflect.Value, val string) error {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(fv.Type().Elem())
+		if err := setValue(elem.Elem(), val); err != nil {
+			return err
+		}
+		fv.Set(elem)
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
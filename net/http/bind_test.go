@@ -0,0 +1,190 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+	ghttp "github.com/Ali-Libra/go-base/net/http"
+)
+
+type Paging struct {
+	Page int `query:"page" json:"page" validate:"min=1"`
+}
+
+type ListUsers struct {
+	Paging
+	Name string   `query:"name" json:"name" validate:"min=2"`
+	Tags []string `query:"tag" json:"tags" validate:"max=2"`
+}
+
+func bindQuery(t *testing.T, query string, v interface{}) error {
+	t.Helper()
+	req := &ghttp.HttpRequest{Request: httptest.NewRequest(http.MethodGet, "/users?"+query, nil)}
+	return req.BindQuery(v)
+}
+
+// fieldErrors 返回校验失败的字段名，不是 ValidationError 时测试失败
+func fieldErrors(t *testing.T, err error) []string {
+	t.Helper()
+	var validationErr *ghttp.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("want ValidationError, got %v", err)
+	}
+
+	var fields []string
+	for _, fieldErr := range validationErr.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	return fields
+}
+
+func TestBindQueryValidatesNumericZero(t *testing.T) {
+	var v ListUsers
+	err := bindQuery(t, "page=0", &v)
+	if fields := fieldErrors(t, err); len(fields) != 1 || fields[0] != "page" {
+		t.Errorf("failed fields = %v, want [page]", fields)
+	}
+}
+
+func TestBindQueryAbsentFieldsSkipRules(t *testing.T) {
+	var v ListUsers
+	if err := bindQuery(t, "page=1", &v); err != nil {
+		t.Errorf("absent name and tags should pass, got %v", err)
+	}
+}
+
+func TestBindQueryEmbeddedStruct(t *testing.T) {
+	var v ListUsers
+	err := bindQuery(t, "page=-1&name=a&tag=x&tag=y&tag=z", &v)
+	if v.Page != -1 || v.Name != "a" || len(v.Tags) != 3 {
+		t.Fatalf("bind result = %+v", v)
+	}
+
+	// 匿名嵌入的字段和绑定时一样平铺，字段名没有前缀
+	want := []string{"page", "name", "tag"}
+	fields := fieldErrors(t, err)
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("failed fields = %v, want %v", fields, want)
+	}
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	type unknownRule struct {
+		Page int `validate:"mni=1"`
+	}
+	type unsupportedKind struct {
+		At time.Time `validate:"min=1"`
+	}
+
+	for _, v := range []interface{}{&unknownRule{}, &unsupportedKind{}} {
+		// 两次都返回同样的错误，不会panic
+		for i := 0; i < 2; i++ {
+			err := ghttp.Validate(v)
+			var validationErr *ghttp.ValidationError
+			if err == nil || errors.As(err, &validationErr) {
+				t.Errorf("Validate(%T) = %v, want tag error", v, err)
+			}
+		}
+	}
+}
+
+func TestBindJSONValidationResponse(t *testing.T) {
+	rec := loggertest.Install(t)
+	handler := ghttp.Chain(ghttp.WrapErrorHandler(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		var body ListUsers
+		if err := req.BindJSON(&body); err != nil {
+			return err
+		}
+		return rsp.WriteJson(body)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"page":0,"name":"bob"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+
+	var body ghttp.ErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Message != "validation failed" || !strings.Contains(w.Body.String(), `"field":"page"`) {
+		t.Errorf("body = %s", w.Body.String())
+	}
+	rec.AssertLogged(t, logger.LogLevelWarn, "page must be at least 1")
+	rec.AssertNotLogged(t, logger.LogLevelError, "")
+}
+
+type base struct {
+	ID string `query:"id" json:"id" validate:"required"`
+}
+
+type PBase struct {
+	ID int `query:"id" json:"id" validate:"min=1"`
+}
+
+// 没有导出的匿名结构体，里面导出的字段同样绑定和校验
+func TestBindQueryUnexportedEmbedded(t *testing.T) {
+	var v struct {
+		base
+		Name string `query:"name"`
+	}
+	if err := bindQuery(t, "id=u1&name=bob", &v); err != nil || v.ID != "u1" {
+		t.Fatalf("bind = %+v, %v", v, err)
+	}
+
+	v.ID = ""
+	if fields := fieldErrors(t, ghttp.Validate(&v)); len(fields) != 1 || fields[0] != "id" {
+		t.Errorf("failed fields = %v, want [id]", fields)
+	}
+}
+
+// 匿名嵌入的结构体指针同样平铺，为nil时不校验，绑定时有参数才分配
+func TestBindQueryEmbeddedPointer(t *testing.T) {
+	type getUser struct {
+		*PBase
+		Name string `query:"name"`
+	}
+
+	var v getUser
+	if err := bindQuery(t, "name=bob", &v); err != nil || v.PBase != nil {
+		t.Fatalf("bind = %+v, %v", v, err)
+	}
+
+	err := bindQuery(t, "id=0", &v)
+	if v.PBase == nil || v.ID != 0 {
+		t.Fatalf("bind = %+v", v)
+	}
+	if fields := fieldErrors(t, err); len(fields) != 1 || fields[0] != "id" {
+		t.Errorf("failed fields = %v, want [id]", fields)
+	}
+}
+
+func TestBindQueryEmbeddedSelf(t *testing.T) {
+	type node struct {
+		*node
+		Name string `query:"name"`
+	}
+
+	var v node
+	if err := bindQuery(t, "name=a", &v); err != nil || v.Name != "a" || v.node != nil {
+		t.Errorf("bind = %+v, %v", v, err)
+	}
+}
+
+// 标签有名字的匿名结构体和JSON一样作为普通字段
+func TestValidateTaggedEmbedded(t *testing.T) {
+	v := struct {
+		PBase `json:"base"`
+	}{}
+	if fields := fieldErrors(t, ghttp.Validate(&v)); len(fields) != 1 || fields[0] != "base.id" {
+		t.Errorf("failed fields = %v, want [base.id]", fields)
+	}
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Ali-Libra/go-base/logger"
+)
+
+// HttpError 带状态码的错误，ErrorHandlerFunc 返回它时按状态码和内容回复
+type HttpError struct {
+	Status  int         // HTTP状态码
+	Code    int         // 业务错误码，为0时使用状态码
+	Message string      // 返回给客户端的内容
+	Details interface{} // 返回给客户端的详细信息，如校验失败的字段
+	Err     error       // 原始错误，只记录日志，不返回给客户端
+}
+
+func NewHttpError(status int, message string) *HttpError {
+	return &HttpError{Status: status, Message: message}
+}
+
+// NewHttpErrorCode 带业务错误码的错误
+func NewHttpErrorCode(status int, code int, message string) *HttpError {
+	return &HttpError{Status: status, Code: code, Message: message}
+}
+
+// WrapHttpError 包装内部错误，返回给客户端的只有状态码对应的描述
+func WrapHttpError(status int, err error) *HttpError {
+	return &HttpError{Status: status, Message: http.StatusText(status), Err: err}
+}
+
+func (e *HttpError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", e.Status, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+func (e *HttpError) Unwrap() error {
+	return e.Err
+}
+
+// toHttpError ValidationError 转成400，其它不是 HttpError 的错误转成500
+func toHttpError(err error) *HttpError {
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return &HttpError{
+			Status:  http.StatusBadRequest,
+			Message: "validation failed",
+			Details: validationErr.Errors,
+			Err:     err,
+		}
+	}
+	return WrapHttpError(http.StatusInternalServerError, err)
+}
+
+// ErrorBody 默认的错误回复格式
+//
+//	{"code":10001,"message":"user not found","request_id":"..."}
+type ErrorBody struct {
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// ErrorRenderer 把错误写入回复，调用时还没有写过状态码
+type ErrorRenderer func(rsp *HttpResponse, req *HttpRequest, err *HttpError)
+
+// DefaultErrorRenderer 按 ErrorBody 的格式回复JSON
+func DefaultErrorRenderer(rsp *HttpResponse, req *HttpRequest, err *HttpError) {
+	body := ErrorBody{
+		Code:    err.Code,
+		Message: err.Message,
+		Details: err.Details,
+	}
+	if body.Code == 0 {
+		body.Code = err.Status
+	}
+	if req != nil {
+		body.RequestID = requestID(req)
+	}
+
+	rsp.Header().Set("Content-Type", "application/json")
+	rsp.WriteHeader(err.Status)
+	json.NewEncoder(rsp).Encode(body)
+}
+
+// requestID 优先使用 SetContext 设置的request_id，没有时使用请求头 X-Request-ID
+func requestID(req *HttpRequest) string {
+	if id := req.GetContext(logger.FieldRequestID); id != "" {
+		return id
+	}
+	return req.Header.Get("X-Request-ID")
+}
@@ -0,0 +1,252 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+	ghttp "github.com/Ali-Libra/go-base/net/http"
+)
+
+// serveE 用 Chain 执行 ErrorHandlerFunc，返回回复和解析后的错误内容
+func serveE(t *testing.T, handler ghttp.ErrorHandlerFunc) (*httptest.ResponseRecorder, ghttp.ErrorBody) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	ghttp.Chain(ghttp.WrapErrorHandler(handler)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body ghttp.ErrorBody
+	if w.Code >= http.StatusBadRequest {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("body %q: %v", w.Body.String(), err)
+		}
+	}
+	return w, body
+}
+
+func TestHandleEStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		code    int
+		message string
+	}{
+		{"http error", ghttp.NewHttpError(http.StatusNotFound, "user not found"), http.StatusNotFound, http.StatusNotFound, "user not found"},
+		{"error code", ghttp.NewHttpErrorCode(http.StatusConflict, 10001, "name taken"), http.StatusConflict, 10001, "name taken"},
+		{"wrapped http error", fmt.Errorf("load: %w", ghttp.NewHttpError(http.StatusForbidden, "denied")), http.StatusForbidden, http.StatusForbidden, "denied"},
+		{"validation error", &ghttp.ValidationError{Errors: []ghttp.FieldError{{Field: "id", Rule: "required", Message: "is required"}}}, http.StatusBadRequest, http.StatusBadRequest, "validation failed"},
+		{"plain error", errors.New("db down"), http.StatusInternalServerError, http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loggertest.Install(t)
+			w, body := serveE(t, func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+				return c.err
+			})
+			if w.Code != c.status || body.Code != c.code || body.Message != c.message {
+				t.Errorf("got %d %+v, want %d code=%d message=%q", w.Code, body, c.status, c.code, c.message)
+			}
+		})
+	}
+}
+
+// 内部错误只记录日志，不返回给客户端
+func TestHandleEHidesInternalError(t *testing.T) {
+	rec := loggertest.Install(t)
+	w, body := serveE(t, func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return ghttp.WrapHttpError(http.StatusBadGateway, errors.New("upstream secret"))
+	})
+	if w.Code != http.StatusBadGateway || body.Message != "Bad Gateway" {
+		t.Errorf("got %d %+v", w.Code, body)
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("internal error leaked: %s", w.Body.String())
+	}
+	rec.AssertLogged(t, logger.LogLevelError, "upstream secret")
+}
+
+// 4xx记录WARN，5xx记录ERROR
+func TestWriteErrorLogLevel(t *testing.T) {
+	rec := loggertest.Install(t)
+	serveE(t, func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return ghttp.NewHttpError(http.StatusNotFound, "missing user")
+	})
+	serveE(t, func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return errors.New("broken db")
+	})
+	rec.AssertLogged(t, logger.LogLevelWarn, "missing user")
+	rec.AssertNotLogged(t, logger.LogLevelError, "missing user")
+	rec.AssertLogged(t, logger.LogLevelError, "broken db")
+}
+
+func TestHandleESuccess(t *testing.T) {
+	rec := loggertest.Install(t)
+	w, _ := serveE(t, func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return rsp.WriteJson(map[string]int{"id": 1})
+	})
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"id":1}` {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+	if entries := rec.Entries(); len(entries) != 0 {
+		t.Errorf("unexpected logs: %+v", entries)
+	}
+}
+
+// 已经开始回复后返回的错误只记录日志，不改变状态码
+func TestHandleEErrorAfterWrite(t *testing.T) {
+	rec := loggertest.Install(t)
+	w := httptest.NewRecorder()
+	ghttp.Chain(ghttp.WrapErrorHandler(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		rsp.WriteHeader(http.StatusAccepted)
+		rsp.Write([]byte("partial"))
+		return errors.New("late failure")
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+	rec.AssertLogged(t, logger.LogLevelError, "late failure")
+}
+
+// decodeError 解析默认格式的错误回复
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) ghttp.ErrorBody {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var body ghttp.ErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestErrorBodyRequestID(t *testing.T) {
+	loggertest.Install(t)
+	s := ghttp.NewHttpServer()
+	s.HandleE("/header", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return ghttp.NewHttpErrorCode(http.StatusNotFound, 10001, "user not found")
+	})
+	// SetContext 设置的request_id优先于请求头
+	s.HandleE("/context", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		req.SetContext(logger.FieldRequestID, "ctx-1")
+		return ghttp.NewHttpError(http.StatusNotFound, "user not found")
+	})
+
+	for path, want := range map[string]string{"/header": "hdr-1", "/context": "ctx-1"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Request-ID", "hdr-1")
+		s.ServeHTTP(w, r)
+
+		body := decodeError(t, w)
+		if w.Code != http.StatusNotFound || body.RequestID != want || body.Message != "user not found" {
+			t.Errorf("%s: got %d %+v, want request_id %q", path, w.Code, body, want)
+		}
+	}
+}
+
+// 原来的 SendError 系列仍然可以使用，按同样的格式回复
+func TestSendErrorVariants(t *testing.T) {
+	cases := []struct {
+		name    string
+		send    func(rsp *ghttp.HttpResponse)
+		status  int
+		code    int
+		message string
+	}{
+		{"SendError", func(rsp *ghttp.HttpResponse) { rsp.SendError("bad thing") }, http.StatusInternalServerError, http.StatusInternalServerError, "bad thing"},
+		{"SendErrorStatus", func(rsp *ghttp.HttpResponse) { rsp.SendErrorStatus(http.StatusNotFound, "no user") }, http.StatusNotFound, http.StatusNotFound, "no user"},
+		{"SendErrorCode", func(rsp *ghttp.HttpResponse) { rsp.SendErrorCode(http.StatusConflict, 10002, "dup") }, http.StatusConflict, 10002, "dup"},
+		{"SendHttpError", func(rsp *ghttp.HttpResponse) { rsp.SendHttpError(errors.New("db down")) }, http.StatusInternalServerError, http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := loggertest.Install(t)
+			w := httptest.NewRecorder()
+			ghttp.Chain(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+				c.send(rsp)
+			}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			body := decodeError(t, w)
+			if w.Code != c.status || body.Code != c.code || body.Message != c.message {
+				t.Errorf("got %d %+v, want %d code=%d message=%q", w.Code, body, c.status, c.code, c.message)
+			}
+			// 主动回复的错误不是panic，不记录堆栈
+			rec.AssertNotLogged(t, logger.LogLevelError, "HttpServer panic")
+		})
+	}
+}
+
+// 真正的panic回复500，panic的内容只记录日志
+func TestPanicResponse(t *testing.T) {
+	rec := loggertest.Install(t)
+	w := httptest.NewRecorder()
+	ghttp.Chain(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		panic("secret state")
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	body := decodeError(t, w)
+	if w.Code != http.StatusInternalServerError || body.Message != "Internal Server Error" {
+		t.Errorf("got %d %+v", w.Code, body)
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("panic value leaked: %s", w.Body.String())
+	}
+	rec.AssertLogged(t, logger.LogLevelError, "HttpServer panic GET /boom: secret state")
+}
+
+func TestSetErrorRenderer(t *testing.T) {
+	loggertest.Install(t)
+	s := ghttp.NewHttpServer()
+	s.SetErrorRenderer(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest, err *ghttp.HttpError) {
+		rsp.Header().Set("Content-Type", "text/plain")
+		rsp.WriteHeader(err.Status)
+		fmt.Fprintf(rsp, "error %d: %s", err.Status, err.Message)
+	})
+	s.HandleE("/returned", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return ghttp.NewHttpError(http.StatusTeapot, "short and stout")
+	})
+	s.Handle("/sent", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.SendErrorStatus(http.StatusForbidden, "denied")
+	})
+	s.Handle("/panic", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		panic("boom")
+	})
+
+	want := map[string]string{
+		"/returned": "error 418: short and stout",
+		"/sent":     "error 403: denied",
+		"/panic":    "error 500: Internal Server Error",
+	}
+	for path, body := range want {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != body || w.Header().Get("Content-Type") != "text/plain" {
+			t.Errorf("%s: got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+// SendOK 和 SendJson 仍然通过panic结束处理，但不当作错误
+func TestSendJson(t *testing.T) {
+	rec := loggertest.Install(t)
+	w := httptest.NewRecorder()
+	ghttp.Chain(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.SendJson(map[string]string{"name": "bob"})
+		t.Error("SendJson returned")
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"name":"bob"}` {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+	if entries := rec.Entries(); len(entries) != 0 {
+		t.Errorf("unexpected logs: %+v", entries)
+	}
+}
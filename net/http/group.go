@@ -0,0 +1,90 @@
+package http
+
+import "strings"
+
+// RouterGroup 共享路径前缀和中间件的一组路由
+// 组内的路由先执行组的中间件，再执行路由自己的中间件，全局中间件和 Handle 一样在处理函数之前执行
+//
+//	api := s.Group("/api/v1", AuthMiddleware)
+//	api.GET("/users/{id}", GetUser)
+//	admin := api.Group("/admin", AdminMiddleware)
+//	admin.DELETE("/users/{id}", DeleteUser)
+type RouterGroup struct {
+	server         *HttpServer
+	prefix         string
+	middleHandlers []HandlerFunc
+}
+
+func (s *HttpServer) Group(prefix string, middleHandlers ...HandlerFunc) *RouterGroup {
+	return &RouterGroup{
+		server:         s,
+		prefix:         groupPrefix(prefix),
+		middleHandlers: middleHandlers,
+	}
+}
+
+// Group 创建子分组，继承当前分组的前缀和中间件
+func (g *RouterGroup) Group(prefix string, middleHandlers ...HandlerFunc) *RouterGroup {
+	return &RouterGroup{
+		server:         g.server,
+		prefix:         g.prefix + groupPrefix(prefix),
+		middleHandlers: g.withMiddleHandlers(middleHandlers),
+	}
+}
+
+func (g *RouterGroup) Handle(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.server.Handle(g.pattern(pattern), handler, g.withMiddleHandlers(middleHandlers)...)
+}
+
+func (g *RouterGroup) HandleE(pattern string, handler ErrorHandlerFunc, middleHandlers ...HandlerFunc) {
+	g.Handle(pattern, WrapErrorHandler(handler), middleHandlers...)
+}
+
+func (g *RouterGroup) HandleMethod(method string, pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.server.HandleMethod(method, g.pattern(pattern), handler, g.withMiddleHandlers(middleHandlers)...)
+}
+
+func (g *RouterGroup) GET(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.HandleMethod(MethodGet, pattern, handler, middleHandlers...)
+}
+
+func (g *RouterGroup) POST(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.HandleMethod(MethodPost, pattern, handler, middleHandlers...)
+}
+
+func (g *RouterGroup) PUT(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.HandleMethod(MethodPut, pattern, handler, middleHandlers...)
+}
+
+func (g *RouterGroup) DELETE(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.HandleMethod(MethodDelete, pattern, handler, middleHandlers...)
+}
+
+func (g *RouterGroup) PATCH(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	g.HandleMethod(MethodPatch, pattern, handler, middleHandlers...)
+}
+
+// groupPrefix 前缀统一为 "/api" 的形式，补上开头的 "/"，去掉结尾的 "/"
+func groupPrefix(prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// pattern 给路径加上分组的前缀，pattern 可以带方法，如 "GET /users"
+func (g *RouterGroup) pattern(pattern string) string {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return g.prefix + pattern
+	}
+	return method + " " + g.prefix + strings.TrimLeft(path, " ")
+}
+
+// withMiddleHandlers 分组的中间件在前，复制一份避免共用底层数组
+func (g *RouterGroup) withMiddleHandlers(middleHandlers []HandlerFunc) []HandlerFunc {
+	handlers := make([]HandlerFunc, 0, len(g.middleHandlers)+len(middleHandlers))
+	handlers = append(handlers, g.middleHandlers...)
+	return append(handlers, middleHandlers...)
+}
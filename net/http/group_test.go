@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	ghttp "github.com/Ali-Libra/go-base/net/http"
+)
+
+// trace 记录中间件和处理函数执行顺序的处理函数
+func trace(calls *[]string, name string) ghttp.HandlerFunc {
+	return func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		*calls = append(*calls, name)
+	}
+}
+
+func TestGroupPrefix(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.Group("api").GET("/users", reply("users"))
+	s.Group("/admin/").Group("v1").GET("/stats", reply("stats"))
+	s.Group("/ping").Handle("GET /", reply("pong"))
+
+	expect(t, s, http.MethodGet, "/api/users", http.StatusOK, "users")
+	expect(t, s, http.MethodGet, "/admin/v1/stats", http.StatusOK, "stats")
+	expect(t, s, http.MethodGet, "/ping/", http.StatusOK, "pong")
+	expect(t, s, http.MethodPost, "/ping/", http.StatusMethodNotAllowed, "")
+	expect(t, s, http.MethodGet, "/users", http.StatusNotFound, "")
+}
+
+// 分组根路径和分组下的路径可以注册不同的方法
+func TestGroupRootAndChildren(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	api := s.Group("/api")
+	api.GET("/", reply("index"))
+	api.POST("/users", reply("created"))
+
+	expect(t, s, http.MethodGet, "/api/", http.StatusOK, "index")
+	expect(t, s, http.MethodPost, "/api/users", http.StatusOK, "created")
+	expect(t, s, http.MethodGet, "/api/users", http.StatusMethodNotAllowed, "")
+}
+
+// 外层分组、内层分组、路由自己的中间件依次执行，和 Handle 一样全局中间件在处理函数之前执行
+func TestGroupMiddlewareOrder(t *testing.T) {
+	var calls []string
+	s := ghttp.NewHttpServer()
+	s.SetMiddleware(trace(&calls, "global"))
+	api := s.Group("/api", trace(&calls, "api"))
+	v1 := api.Group("/v1", trace(&calls, "v1"))
+	v1.GET("/users", trace(&calls, "handler"), trace(&calls, "route"))
+	api.GET("/health", trace(&calls, "health"))
+
+	expect(t, s, http.MethodGet, "/api/v1/users", http.StatusOK, "")
+	if got := strings.Join(calls, ","); got != "api,v1,route,global,handler" {
+		t.Errorf("calls = %s", got)
+	}
+
+	calls = nil
+	expect(t, s, http.MethodGet, "/api/health", http.StatusOK, "")
+	if got := strings.Join(calls, ","); got != "api,global,health" {
+		t.Errorf("calls = %s", got)
+	}
+}
+
+// 同一分组下的子分组不共用中间件
+func TestGroupSiblings(t *testing.T) {
+	var calls []string
+	s := ghttp.NewHttpServer()
+	api := s.Group("/api", trace(&calls, "api"))
+	admin := api.Group("/admin", trace(&calls, "admin"))
+	public := api.Group("/public", trace(&calls, "public"))
+	admin.GET("/users", trace(&calls, "admin users"))
+	public.GET("/users", trace(&calls, "public users"))
+
+	expect(t, s, http.MethodGet, "/api/admin/users", http.StatusOK, "")
+	if got := strings.Join(calls, ","); got != "api,admin,admin users" {
+		t.Errorf("calls = %s", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/Ali-Libra/go-base/logger"
@@ -13,12 +14,36 @@ import (
 
 type HandlerFunc func(*HttpResponse, *HttpRequest)
 
+// ErrorHandlerFunc 不用panic结束请求的处理函数
+// 返回 HttpError 时按它的状态码回复，返回其它错误时回复500
+//
+//	s.HandleE("/user", func(rsp *HttpResponse, req *HttpRequest) error {
+//		user, err := loadUser(req)
+//		if err != nil {
+//			return NewHttpError(http.StatusNotFound, "user not found")
+//		}
+//		return rsp.WriteJson(user)
+//	})
+type ErrorHandlerFunc func(*HttpResponse, *HttpRequest) error
+
+// WrapErrorHandler 把 ErrorHandlerFunc 转成 HandlerFunc，可以和原来的中间件一起使用
+func WrapErrorHandler(handler ErrorHandlerFunc) HandlerFunc {
+	return func(rsp *HttpResponse, req *HttpRequest) {
+		if err := handler(rsp, req); err != nil {
+			rsp.WriteError(err)
+		}
+	}
+}
+
 type HttpServer struct {
 	server      *http.Server
 	mux         *http.ServeMux
 	timeout     time.Duration
 	idleTimeout time.Duration
 	middlewares []Middleware
+
+	errorRenderer ErrorRenderer
+	routes        map[string]*route // 按路径注册的处理函数，同一路径只在ServeMux注册一次
 }
 
 func NewHttpServer() *HttpServer {
@@ -27,13 +52,14 @@ func NewHttpServer() *HttpServer {
 		timeout:     5 * time.Second,
 		idleTimeout: 120 * time.Second,
 		middlewares: make([]Middleware, 0),
+		routes:      make(map[string]*route),
 	}
 }
 
 func (s *HttpServer) Run(port string) {
 	s.server = &http.Server{
 		Addr:         port,
-		Handler:      s.mux,
+		Handler:      s,
 		ReadTimeout:  s.timeout,
 		WriteTimeout: s.timeout,
 		IdleTimeout:  s.idleTimeout,
@@ -44,6 +70,11 @@ func (s *HttpServer) Run(port string) {
 	s.server.ListenAndServe()
 }
 
+// ServeHTTP 按注册的路由处理请求，可以用于 httptest 或挂到其它服务下
+func (s *HttpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
 func (s *HttpServer) Close() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -66,7 +97,22 @@ func (s *HttpServer) SetMiddleware(middleHandler HandlerFunc) {
 	s.middlewares = append(s.middlewares, middleware)
 }
 
+// Handle 处理这个路径所有方法的请求，pattern 带方法时和 HandleMethod 相同，如 "GET /users"
 func (s *HttpServer) Handle(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		s.HandleMethod(method, strings.TrimLeft(path, " "), handler, middleHandlers...)
+		return
+	}
+
+	r := s.routeOf(pattern)
+	if r.fallback != nil {
+		panic("http: multiple registrations for " + pattern)
+	}
+	r.fallback = s.buildHandler(handler, middleHandlers)
+}
+
+// buildHandler 把全局中间件和路由自己的中间件包装到处理函数外面
+func (s *HttpServer) buildHandler(handler HandlerFunc, middleHandlers []HandlerFunc) http.Handler {
 	mws := make([]Middleware, 0)
 	mws = append(mws, s.middlewares...)
 	for i := len(middleHandlers) - 1; i >= 0; i-- {
@@ -80,5 +126,16 @@ func (s *HttpServer) Handle(pattern string, handler HandlerFunc, middleHandlers
 		mws = append(mws, middle)
 	}
 
-	s.mux.Handle(pattern, Chain(handler, mws...))
+	return chain(handler, s, mws...)
+}
+
+// SetErrorRenderer 设置错误回复的格式，默认为 DefaultErrorRenderer 的JSON格式
+// 需要在Run之前调用
+func (s *HttpServer) SetErrorRenderer(renderer ErrorRenderer) {
+	s.errorRenderer = renderer
+}
+
+// HandleE 和 Handle 相同，处理函数通过返回值回复错误
+func (s *HttpServer) HandleE(pattern string, handler ErrorHandlerFunc, middleHandlers ...HandlerFunc) {
+	s.Handle(pattern, WrapErrorHandler(handler), middleHandlers...)
 }
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"fmt"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -12,18 +11,32 @@ import (
 type Middleware func(HandlerFunc) HandlerFunc
 
 func Chain(f HandlerFunc, middlewares ...Middleware) http.Handler {
+	return chain(f, nil, middlewares...)
+}
+
+// chain server不为nil时使用它设置的错误回复格式
+func chain(f HandlerFunc, server *HttpServer, middlewares ...Middleware) http.Handler {
 	for _, m := range middlewares {
 		f = m(f)
 	}
 
 	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rsp := &HttpResponse{ResponseWriter: w}
 		req := &HttpRequest{Request: r}
+		rsp := &HttpResponse{ResponseWriter: w, req: req}
+		if server != nil {
+			rsp.renderer = server.errorRenderer
+		}
 
 		defer func() {
 			if err := recover(); err != nil && !rsp.success {
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(fmt.Sprintf("%v", err)))
+				// SendError, SendErrorStatus, SendErrorCode 和 SendHttpError
+				if httpErr, ok := err.(*HttpError); ok && rsp.failed {
+					rsp.WriteError(httpErr)
+					return
+				}
+				// 真正的panic只记录日志，不把panic的内容返回给客户端
+				logger.ErrorStack("HttpServer panic %s %s: %v", r.Method, r.URL.Path, err)
+				rsp.renderError(NewHttpError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)))
 			}
 		}()
 		f(rsp, req)
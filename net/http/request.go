@@ -1,8 +1,11 @@
 package http
 
 import (
+	"context"
 	"io"
 	"net/http"
+
+	"github.com/Ali-Libra/go-base/logger"
 )
 
 type HttpRequest struct {
@@ -18,6 +21,11 @@ func (req *HttpRequest) ReadBody() ([]byte, error) {
 	return body, nil
 }
 
+// PathParam 返回路径参数，如 /users/{id} 中的id
+func (req *HttpRequest) PathParam(name string) string {
+	return req.PathValue(name)
+}
+
 func (req *HttpRequest) SetContext(key string, value string) {
 	if req.contexts == nil {
 		req.contexts = make(map[string]string)
@@ -31,3 +39,22 @@ func (req *HttpRequest) GetContext(key string) string {
 	}
 	return req.contexts[key]
 }
+
+// LogContext 返回携带 request_id/trace_id/span_id/user_id 的context
+// 这些值通过 SetContext 设置，供 logger.InfoCtx 等方法输出
+func (req *HttpRequest) LogContext() context.Context {
+	ctx := req.Context()
+	if id := req.GetContext(logger.FieldRequestID); id != "" {
+		ctx = logger.WithRequestID(ctx, id)
+	}
+	if id := req.GetContext(logger.FieldTraceID); id != "" {
+		ctx = logger.WithTraceID(ctx, id)
+	}
+	if id := req.GetContext(logger.FieldSpanID); id != "" {
+		ctx = logger.WithSpanID(ctx, id)
+	}
+	if id := req.GetContext(logger.FieldUserID); id != "" {
+		ctx = logger.WithUserID(ctx, id)
+	}
+	return ctx
+}
@@ -10,25 +10,117 @@ import (
 type HttpResponse struct {
 	http.ResponseWriter
 	success bool
+	failed  bool // SendError主动结束的请求，不是panic
+	status  int  // 已经回复的状态码，0表示还没有回复
+
+	req      *HttpRequest
+	renderer ErrorRenderer
+}
+
+func (rsp *HttpResponse) WriteHeader(status int) {
+	if rsp.status != 0 {
+		return
+	}
+	rsp.status = status
+	rsp.ResponseWriter.WriteHeader(status)
+}
+
+func (rsp *HttpResponse) Write(data []byte) (int, error) {
+	if rsp.status == 0 {
+		rsp.status = http.StatusOK
+	}
+	return rsp.ResponseWriter.Write(data)
+}
+
+// Unwrap 供 http.ResponseController 使用
+func (rsp *HttpResponse) Unwrap() http.ResponseWriter {
+	return rsp.ResponseWriter
+}
+
+// Status 返回已经回复的状态码，还没有回复时为0
+func (rsp *HttpResponse) Status() int {
+	return rsp.status
+}
+
+// Written 是否已经开始回复
+func (rsp *HttpResponse) Written() bool {
+	return rsp.status != 0
+}
+
+// WriteOK 和 SendOK 相同，但不会panic，用于 ErrorHandlerFunc
+func (rsp *HttpResponse) WriteOK() {
+	rsp.WriteHeader(http.StatusOK)
+}
+
+// WriteJson 和 SendJson 相同，但不会panic，用于 ErrorHandlerFunc
+func (rsp *HttpResponse) WriteJson(data interface{}) error {
+	rsp.Header().Set("Content-Type", "application/json")
+	rsp.WriteHeader(http.StatusOK)
+	if data == nil {
+		return nil
+	}
+	return json.NewEncoder(rsp).Encode(data)
+}
+
+// WriteError 按 HttpError 的状态码和内容回复，ValidationError 回复400，其它错误回复500
+// 回复的格式由 HttpServer.SetErrorRenderer 决定，已经开始回复时只记录日志
+func (rsp *HttpResponse) WriteError(err error) {
+	httpErr := toHttpError(err)
+
+	if httpErr.Status >= http.StatusInternalServerError {
+		logger.Error("HttpResponse Error: %v", err)
+	} else {
+		logger.Warn("HttpResponse Error: %v", err)
+	}
+	rsp.renderError(httpErr)
+}
+
+// renderError 按设置的格式回复错误，不记录日志，已经开始回复时不处理
+func (rsp *HttpResponse) renderError(httpErr *HttpError) {
+	if rsp.Written() {
+		return
+	}
+	renderer := rsp.renderer
+	if renderer == nil {
+		renderer = DefaultErrorRenderer
+	}
+	renderer(rsp, rsp.req, httpErr)
 }
 
+// SendError 回复500错误并结束处理，rspTxt作为错误内容返回给客户端
 func (rsp *HttpResponse) SendError(rspTxt string) {
-	logger.Error("HttpResponse Error: %s", rspTxt)
-	panic(rspTxt)
+	rsp.failed = true
+	panic(NewHttpError(http.StatusInternalServerError, rspTxt))
+}
+
+// SendErrorStatus 按状态码回复错误并结束处理
+func (rsp *HttpResponse) SendErrorStatus(status int, message string) {
+	rsp.SendErrorCode(status, 0, message)
+}
+
+// SendErrorCode 按状态码和业务错误码回复错误并结束处理
+func (rsp *HttpResponse) SendErrorCode(status int, code int, message string) {
+	rsp.failed = true
+	panic(NewHttpErrorCode(status, code, message))
+}
+
+// SendHttpError 和 WriteError 相同的方式回复错误并结束处理
+//
+//	if err := req.BindJSON(&body); err != nil {
+//		rsp.SendHttpError(err)
+//	}
+func (rsp *HttpResponse) SendHttpError(err error) {
+	rsp.failed = true
+	panic(toHttpError(err))
 }
 
 func (rsp *HttpResponse) SendOK() {
-	rsp.WriteHeader(200)
+	rsp.WriteOK()
 	rsp.success = true
 	panic("success")
 }
 func (rsp *HttpResponse) SendJson(data interface{}) {
-	rsp.Header().Set("Content-Type", "application/json")
-	rsp.WriteHeader(200)
 	rsp.success = true
-
-	if data != nil {
-		json.NewEncoder(rsp).Encode(data)
-	}
+	rsp.WriteJson(data)
 	panic("success")
 }
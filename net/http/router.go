@@ -0,0 +1,98 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// route 一个路径上注册的处理函数，路径只在ServeMux注册一次，按方法分发
+// 不把方法交给ServeMux，避免 "GET /" 和 "/health" 这样的注册互相冲突
+type route struct {
+	methods    map[string]http.Handler
+	fallback   http.Handler // Handle注册的不区分方法的处理函数，处理没有单独注册的方法
+	notAllowed http.Handler // 自动回复的OPTIONS和405，只经过全局中间件
+}
+
+func (s *HttpServer) GET(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	s.HandleMethod(MethodGet, pattern, handler, middleHandlers...)
+}
+
+func (s *HttpServer) POST(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	s.HandleMethod(MethodPost, pattern, handler, middleHandlers...)
+}
+
+func (s *HttpServer) PUT(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	s.HandleMethod(MethodPut, pattern, handler, middleHandlers...)
+}
+
+func (s *HttpServer) DELETE(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	s.HandleMethod(MethodDelete, pattern, handler, middleHandlers...)
+}
+
+func (s *HttpServer) PATCH(pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	s.HandleMethod(MethodPatch, pattern, handler, middleHandlers...)
+}
+
+// HandleMethod 只处理指定方法的请求，路径中可以带参数，如 /users/{id}
+// 同一路径的其它方法回复405并带上 Allow 头，GET同时处理HEAD
+// 同一路径用Handle注册过时，其它方法交给Handle注册的处理函数
+// 没有注册OPTIONS时，自动回复204和 Allow 头
+func (s *HttpServer) HandleMethod(method string, pattern string, handler HandlerFunc, middleHandlers ...HandlerFunc) {
+	r := s.routeOf(pattern)
+	if _, ok := r.methods[method]; ok {
+		panic("http: multiple registrations for " + method + " " + pattern)
+	}
+	r.methods[method] = s.buildHandler(handler, middleHandlers)
+}
+
+// routeOf 返回路径对应的route，第一次使用时在ServeMux注册
+func (s *HttpServer) routeOf(pattern string) *route {
+	if r, ok := s.routes[pattern]; ok {
+		return r
+	}
+
+	r := &route{methods: make(map[string]http.Handler)}
+	r.notAllowed = s.buildHandler(func(rsp *HttpResponse, req *HttpRequest) {
+		rsp.Header().Set("Allow", r.allow())
+		if req.Method == MethodOptions {
+			rsp.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rsp.renderError(NewHttpError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed)))
+	}, nil)
+	s.mux.Handle(pattern, r)
+	s.routes[pattern] = r
+	return r
+}
+
+func (r *route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, ok := r.methods[req.Method]
+	if !ok && req.Method == MethodHead {
+		h, ok = r.methods[MethodGet]
+	}
+	if !ok {
+		h = r.fallback
+	}
+	if h == nil {
+		h = r.notAllowed
+	}
+	h.ServeHTTP(w, req)
+}
+
+// allow 返回 Allow 头，注册了GET时同时支持HEAD
+func (r *route) allow() string {
+	methods := make([]string, 0, len(r.methods)+2)
+	for method := range r.methods {
+		methods = append(methods, method)
+	}
+	if _, ok := r.methods[MethodOptions]; !ok {
+		methods = append(methods, MethodOptions)
+	}
+	_, get := r.methods[MethodGet]
+	if _, head := r.methods[MethodHead]; get && !head {
+		methods = append(methods, MethodHead)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,175 @@
+package http_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ali-Libra/go-base/logger"
+	"github.com/Ali-Libra/go-base/logger/loggertest"
+	ghttp "github.com/Ali-Libra/go-base/net/http"
+)
+
+// reply 回复固定内容的处理函数
+func reply(body string) ghttp.HandlerFunc {
+	return func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		fmt.Fprint(rsp, body)
+	}
+}
+
+func serve(s *ghttp.HttpServer, method string, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+// expect 检查状态码和回复内容，body为空时不检查内容
+func expect(t *testing.T, s *ghttp.HttpServer, method string, path string, status int, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := serve(s, method, path)
+	if w.Code != status || (body != "" && w.Body.String() != body) {
+		t.Errorf("%s %s = %d %q, want %d %q", method, path, w.Code, w.Body.String(), status, body)
+	}
+	return w
+}
+
+func TestRouterPathParams(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.GET("/users/{id}", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		fmt.Fprint(rsp, "user "+req.PathParam("id"))
+	})
+	// 更具体的路径和带参数的路径可以同时注册
+	s.GET("/users/me", reply("me"))
+
+	expect(t, s, http.MethodGet, "/users/7", http.StatusOK, "user 7")
+	expect(t, s, http.MethodGet, "/users/me", http.StatusOK, "me")
+	expect(t, s, http.MethodHead, "/users/7", http.StatusOK, "")
+	expect(t, s, http.MethodGet, "/orders/7", http.StatusNotFound, "")
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	rec := loggertest.Install(t)
+	s := ghttp.NewHttpServer()
+	s.SetMiddleware(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.Header().Set("X-Global", "1")
+	})
+	routeMiddle := func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.Header().Set("X-Route", "1")
+	}
+	s.GET("/users/{id}", reply("get"), routeMiddle)
+	s.DELETE("/users/{id}", reply("delete"))
+
+	w := expect(t, s, http.MethodPost, "/users/7", http.StatusMethodNotAllowed, "")
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+	body := decodeError(t, w)
+	if body.Code != http.StatusMethodNotAllowed || body.Message != "Method Not Allowed" {
+		t.Errorf("body = %+v", body)
+	}
+	// 经过全局中间件，不经过某个路由自己的中间件
+	if w.Header().Get("X-Global") != "1" || w.Header().Get("X-Route") != "" {
+		t.Errorf("headers = %v", w.Header())
+	}
+	// 405是客户端的问题，不记录日志
+	if entries := rec.Entries(); len(entries) != 0 {
+		t.Errorf("unexpected logs: %+v", entries)
+	}
+}
+
+func TestRouterMethodNotAllowedRenderer(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.SetErrorRenderer(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest, err *ghttp.HttpError) {
+		rsp.WriteHeader(err.Status)
+		fmt.Fprintf(rsp, "error %d", err.Status)
+	})
+	s.POST("/users", reply("created"))
+
+	w := expect(t, s, http.MethodGet, "/users", http.StatusMethodNotAllowed, "error 405")
+	if allow := w.Header().Get("Allow"); allow != "OPTIONS, POST" {
+		t.Errorf("Allow = %q", allow)
+	}
+}
+
+func TestRouterOptions(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.SetMiddleware(func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.Header().Set("X-Global", "1")
+	})
+	s.GET("/users", reply("list"))
+	s.POST("/users", reply("created"))
+	s.GET("/cors", reply("cors"))
+	s.HandleMethod(ghttp.MethodOptions, "/cors", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) {
+		rsp.Header().Set("Access-Control-Allow-Origin", "*")
+		rsp.WriteHeader(http.StatusOK)
+	})
+
+	w := expect(t, s, http.MethodOptions, "/users", http.StatusNoContent, "")
+	if w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" || w.Header().Get("X-Global") != "1" {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	// 自己注册的OPTIONS不自动回复
+	w = expect(t, s, http.MethodOptions, "/cors", http.StatusOK, "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("headers = %v", w.Header())
+	}
+	expect(t, s, http.MethodOptions, "/missing", http.StatusNotFound, "")
+}
+
+// Handle注册的路径处理没有单独注册的方法
+func TestRouterHandleWithMethods(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.GET("/items", reply("get"))
+	s.Handle("/items", reply("any"))
+	s.Handle("/health", reply("health"))
+	s.GET("/", reply("root"))
+
+	expect(t, s, http.MethodGet, "/items", http.StatusOK, "get")
+	expect(t, s, http.MethodPost, "/items", http.StatusOK, "any")
+	expect(t, s, http.MethodOptions, "/items", http.StatusOK, "any")
+	expect(t, s, http.MethodPost, "/health", http.StatusOK, "health")
+	expect(t, s, http.MethodGet, "/", http.StatusOK, "root")
+	w := expect(t, s, http.MethodPost, "/", http.StatusMethodNotAllowed, "")
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+}
+
+// 子树路径和它下面的路径分别注册方法
+func TestRouterSubtree(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.GET("/api/", reply("index"))
+	s.POST("/api/users", reply("created"))
+
+	expect(t, s, http.MethodGet, "/api/", http.StatusOK, "index")
+	expect(t, s, http.MethodGet, "/api/other", http.StatusOK, "index")
+	expect(t, s, http.MethodPost, "/api/users", http.StatusOK, "created")
+	expect(t, s, http.MethodPost, "/api/", http.StatusMethodNotAllowed, "")
+}
+
+// 同一方法和路径重复注册时和 http.ServeMux 一样panic
+func TestRouterDuplicate(t *testing.T) {
+	s := ghttp.NewHttpServer()
+	s.GET("/users", reply("list"))
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate route did not panic")
+		}
+	}()
+	s.GET("/users", reply("list"))
+}
+
+func TestRouterLogsHandlerErrorsOnly(t *testing.T) {
+	rec := loggertest.Install(t)
+	s := ghttp.NewHttpServer()
+	s.HandleE("GET /fail", func(rsp *ghttp.HttpResponse, req *ghttp.HttpRequest) error {
+		return ghttp.NewHttpError(http.StatusConflict, "conflict")
+	})
+
+	expect(t, s, http.MethodPost, "/fail", http.StatusMethodNotAllowed, "")
+	rec.AssertNotLogged(t, logger.LogLevelWarn, "")
+	expect(t, s, http.MethodGet, "/fail", http.StatusConflict, "")
+	rec.AssertLogged(t, logger.LogLevelWarn, "conflict")
+}
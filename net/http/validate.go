@@ -0,0 +1,387 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Ali-Libra/go-base/util"
+)
+
+// FieldError 一个字段没有通过的校验规则
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError 绑定或校验失败的字段，WriteError 和 SendHttpError 按400回复
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		msgs = append(msgs, fieldErr.Field+" "+fieldErr.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate 按结构体字段的 validate 标签校验，字段名使用json标签
+//
+//	type CreateUser struct {
+//		Name  string `json:"name" validate:"required,min=2,max=20"`
+//		Email string `json:"email" validate:"required,email"`
+//		Code  string `json:"code" validate:"len=6,regexp=^[0-9]+$"`
+//	}
+//
+// 支持 required, min, max, len, email, regexp
+// min/max 对数字比较数值，对字符串比较字符数，对切片比较长度
+// 没有传的字段(nil指针、空字符串、nil切片)不做required之外的校验，数字0照常校验
+// required 对数字要求不为0，需要区分0和没有传时使用指针
+// regexp中可能有逗号，必须放在最后，匿名嵌入的结构体和绑定时一样与外层平铺
+// 每个类型第一次校验时检查标签，写错的标签返回错误，不会panic
+func Validate(v interface{}) error {
+	return validate(v, "json")
+}
+
+func validate(v interface{}, nameTag string) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	rules, err := rulesOf(rv.Type(), nameTag)
+	if err != nil {
+		return err
+	}
+
+	var errs []FieldError
+	rules.validate(rv, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// structRules 一个结构体类型解析好的校验规则
+type structRules struct {
+	fields []fieldRules
+}
+
+type fieldRules struct {
+	index    int
+	name     string
+	rules    []validateRule
+	nested   *structRules // 字段是结构体或结构体指针时，里面字段的规则
+	embedded bool         // 匿名嵌入的结构体，nested 里的字段没有前缀
+}
+
+type validateRule struct {
+	name  string // min
+	text  string // min=2
+	param string // 2
+	value float64
+	reg   *regexp.Regexp
+}
+
+type rulesKey struct {
+	typ     reflect.Type
+	nameTag string
+}
+
+type rulesEntry struct {
+	rules *structRules
+	err   error
+}
+
+var rulesCache sync.Map // rulesKey -> rulesEntry
+
+// rulesOf 返回类型的校验规则，第一次使用时解析并缓存，标签错误也会缓存
+func rulesOf(t reflect.Type, nameTag string) (*structRules, error) {
+	key := rulesKey{typ: t, nameTag: nameTag}
+	if entry, ok := rulesCache.Load(key); ok {
+		return entry.(rulesEntry).rules, entry.(rulesEntry).err
+	}
+
+	rules, err := compileStruct(t, nameTag, make(map[reflect.Type]*structRules))
+	if err != nil {
+		err = fmt.Errorf("validate %s: %w", t, err)
+	}
+	rulesCache.Store(key, rulesEntry{rules: rules, err: err})
+	return rules, err
+}
+
+// compileStruct seen 记录正在解析的类型，结构体引用自己时复用同一份规则
+func compileStruct(t reflect.Type, nameTag string, seen map[reflect.Type]*structRules) (*structRules, error) {
+	if rules, ok := seen[t]; ok {
+		return rules, nil
+	}
+
+	rules := &structRules{}
+	seen[t] = rules
+	if err := rules.compileFields(t, nameTag, seen); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *structRules) compileFields(t reflect.Type, nameTag string, seen map[reflect.Type]*structRules) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// 匿名嵌入的结构体，没有导出的结构体里导出的字段同样平铺
+		if et, ok := embeddedStruct(field, nameTag); ok {
+			nested, err := compileStruct(et, nameTag, seen)
+			if err != nil {
+				return err
+			}
+			s.fields = append(s.fields, fieldRules{index: i, nested: nested, embedded: true})
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field, nameTag)
+		if name == "-" {
+			continue
+		}
+
+		f := fieldRules{index: i, name: name}
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			rules, err := parseRules(field.Type, tag)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			f.rules = rules
+		}
+
+		// 嵌套的结构体
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			nested, err := compileStruct(ft, nameTag, seen)
+			if err != nil {
+				return err
+			}
+			f.nested = nested
+		}
+		s.fields = append(s.fields, f)
+	}
+	return nil
+}
+
+// embeddedStruct 和 encoding/json 相同，匿名嵌入且标签没有名字的结构体或结构体指针和外层平铺
+func embeddedStruct(field reflect.StructField, nameTag string) (reflect.Type, bool) {
+	if !field.Anonymous {
+		return nil, false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(nameTag), ",")
+	return t, name == ""
+}
+
+// parseRules 解析 validate 标签，检查规则名、参数和字段类型
+func parseRules(t reflect.Type, tag string) ([]validateRule, error) {
+	kind := t.Kind()
+	if kind == reflect.Pointer {
+		kind = t.Elem().Kind()
+	}
+
+	var rules []validateRule
+	for tag != "" {
+		var text string
+		tag = strings.TrimLeft(tag, " ")
+		if strings.HasPrefix(tag, "regexp=") {
+			text, tag = tag, ""
+		} else {
+			text, tag, _ = strings.Cut(tag, ",")
+		}
+		text = strings.TrimSpace(text)
+		name, param, _ := strings.Cut(text, "=")
+		rule := validateRule{name: name, text: text, param: param}
+
+		switch name {
+		case "required":
+		case "min", "max", "len":
+			if !sizeKind(kind) {
+				return nil, fmt.Errorf("rule %s does not support %s", name, t)
+			}
+			value, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %s", text)
+			}
+			rule.value = value
+		case "email":
+			if kind != reflect.String {
+				return nil, fmt.Errorf("rule %s does not support %s", name, t)
+			}
+		case "regexp":
+			if kind != reflect.String {
+				return nil, fmt.Errorf("rule %s does not support %s", name, t)
+			}
+			reg, err := regexp.Compile(param)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %s: %w", text, err)
+			}
+			rule.reg = reg
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *structRules) validate(rv reflect.Value, prefix string, errs *[]FieldError) {
+	for _, f := range s.fields {
+		fv := rv.Field(f.index)
+		if f.embedded {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			f.nested.validate(fv, prefix, errs)
+			continue
+		}
+
+		name := prefix + f.name
+
+		if fieldErr := checkRules(fv, f.rules); fieldErr != nil {
+			fieldErr.Field = name
+			*errs = append(*errs, *fieldErr)
+			continue
+		}
+
+		if f.nested == nil {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		f.nested.validate(fv, name+".", errs)
+	}
+}
+
+// fieldName 标签中的名字，没有时使用字段名
+func fieldName(field reflect.StructField, nameTag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(nameTag), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// checkRules 返回第一个没有通过的规则
+func checkRules(fv reflect.Value, rules []validateRule) *FieldError {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv = reflect.Value{}
+		} else {
+			fv = fv.Elem()
+		}
+	}
+
+	// 没有传的字段：nil指针、空字符串、nil的切片和map
+	absent := !fv.IsValid()
+	if !absent {
+		switch fv.Kind() {
+		case reflect.String:
+			absent = fv.Len() == 0
+		case reflect.Slice, reflect.Map:
+			absent = fv.IsNil()
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.name == "required" {
+			if absent || fv.IsZero() {
+				return &FieldError{Rule: rule.name, Message: "is required"}
+			}
+			continue
+		}
+		if absent {
+			continue
+		}
+
+		switch rule.name {
+		case "min":
+			if size(fv) < rule.value {
+				return &FieldError{Rule: rule.text, Message: "must be at least " + rule.param + sizeUnit(fv)}
+			}
+		case "max":
+			if size(fv) > rule.value {
+				return &FieldError{Rule: rule.text, Message: "must be at most " + rule.param + sizeUnit(fv)}
+			}
+		case "len":
+			if size(fv) != rule.value {
+				return &FieldError{Rule: rule.text, Message: "must be exactly " + rule.param + sizeUnit(fv)}
+			}
+		case "email":
+			if !util.VerifyEmailFormat(fv.String()) {
+				return &FieldError{Rule: rule.name, Message: "must be a valid email"}
+			}
+		case "regexp":
+			if !rule.reg.MatchString(fv.String()) {
+				return &FieldError{Rule: rule.name, Message: "does not match " + rule.param}
+			}
+		}
+	}
+	return nil
+}
+
+// sizeKind min/max/len 支持的类型
+func sizeKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// size 数字返回数值，字符串返回字符数，切片返回长度，类型已经由 sizeKind 检查过
+func size(fv reflect.Value) float64 {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fv.Float()
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String()))
+	}
+	return float64(fv.Len())
+}
+
+func sizeUnit(fv reflect.Value) string {
+	switch fv.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}